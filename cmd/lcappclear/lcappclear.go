@@ -25,6 +25,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"github.com/fatima-go/fatima-cmd/revision"
 	"github.com/fatima-go/fatima-cmd/share"
 	"io/ioutil"
 	"os"
@@ -62,6 +63,10 @@ func clearBackupFiles() {
 
 	files := make([]string, 0)
 	filepath.Walk(appDir, func(path string, f os.FileInfo, err error) error {
+		if f != nil && f.IsDir() && isPinnedRevisionDir(path) {
+			fmt.Printf("skip pinned process : %s\n", filepath.Base(path))
+			return filepath.SkipDir
+		}
 		if strings.HasSuffix(path, ".backup") {
 			files = append(files, path)
 		}
@@ -119,6 +124,12 @@ func inspectAppVersion(path string) {
 		return
 	}
 
+	proc := filepath.Base(path)
+	if revision.IsPinned(proc) {
+		fmt.Printf("skip pinned process : %s\n", proc)
+		return
+	}
+
 	originName := filepath.Base(eval)
 	for _, f := range files {
 		if f.Name() == originName {
@@ -129,6 +140,15 @@ func inspectAppVersion(path string) {
 	}
 }
 
+// isPinnedRevisionDir path 가 pin 된 프로세스의 revision 폴더인지 확인한다
+func isPinnedRevisionDir(path string) bool {
+	revisionDir := filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderApp, share.FatimaFolderRevision)
+	if filepath.Dir(path) != revisionDir {
+		return false
+	}
+	return revision.IsPinned(filepath.Base(path))
+}
+
 func removeDir(path string) {
 	command := fmt.Sprintf("rm -rf %s", path)
 	_, err := ExecuteShell(command)
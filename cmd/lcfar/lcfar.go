@@ -0,0 +1,127 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 2:10
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	. "github.com/fatima-go/fatima-cmd/domain"
+	"github.com/fatima-go/fatima-cmd/far"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"strings"
+)
+
+var usage = `usage: %s command [option] file
+
+inspect 'far' fatima package file
+
+command :
+  inspect    verify platform binaries in far by their elf/mach-o header
+
+optional arguments:
+  -t    string
+        target platform to verify. e.g) linux_amd64
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Printf(usage, os.Args[0])
+	}
+	inspectCommand := flag.NewFlagSet("inspect", flag.ExitOnError)
+	inspectCommand.Usage = flag.Usage
+
+	var target string
+	inspectCommand.StringVar(&target, "t", "", "target platform to verify. e.g) linux_amd64")
+
+	flag.Parse()
+	if len(flag.Args()) < 2 {
+		flag.Usage()
+		return
+	}
+
+	switch flag.Args()[0] {
+	case "inspect":
+		inspectCommand.Parse(flag.Args()[1:])
+	default:
+		flag.Usage()
+		return
+	}
+
+	if len(inspectCommand.Args()) < 1 {
+		flag.Usage()
+		return
+	}
+
+	farFile := inspectCommand.Args()[0]
+	if !share.IsFileExist(farFile) {
+		fmt.Printf("far file doesn't exist : %s\n", farFile)
+		os.Exit(1)
+	}
+
+	if !inspect(farFile, target) {
+		os.Exit(1)
+	}
+}
+
+func inspect(farFile, target string) bool {
+	list, err := far.InspectPlatforms(farFile)
+	if err != nil {
+		fmt.Printf("fail to inspect %s : %s\n", farFile, err.Error())
+		return false
+	}
+
+	if len(list) == 0 {
+		fmt.Printf("there is no platform binary in %s\n", farFile)
+		return true
+	}
+
+	data := make([][]string, 0)
+	for _, b := range list {
+		result := "OK"
+		if !b.IsBinary() {
+			result = "-"
+		} else if !b.MatchesDir() {
+			result = "MISMATCH"
+		}
+		data = append(data, []string{b.Dir, b.Name, b.Format, b.Platform(), result})
+	}
+	share.PrintTable([]string{"dir", "file", "format", "header", "result"}, data)
+
+	var targetPlatform PlatformResp
+	if len(target) > 0 {
+		goos, goarch, ok := strings.Cut(target, "_")
+		if !ok {
+			fmt.Printf("invalid target platform : %s\n", target)
+			return false
+		}
+		targetPlatform = PlatformResp{OS: goos, Architecture: goarch}
+	}
+
+	err = far.VerifyPlatform(farFile, targetPlatform)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		return false
+	}
+
+	fmt.Printf("all platform binaries are valid\n")
+	return true
+}
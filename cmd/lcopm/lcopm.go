@@ -0,0 +1,256 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 12:40
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/config"
+	"github.com/fatima-go/fatima-cmd/localproc"
+	"github.com/fatima-go/fatima-cmd/share"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var usage = `usage: %s command [options]
+
+display local opm(management plane) process status without jupiter/juno
+
+positional arguments:
+  command		status
+
+optional arguments:
+  --json		print as json
+  --ports		http port of opm process. e.g) jupiter:9190,juno:9180
+			overrides port of fatima-package.yaml and default port(jupiter:9190)
+  --timeout		http check timeout (default 2s)
+
+example :
+
+lcopm status			: display pid, liveness, uptime, revision and http endpoint of opm processes
+lcopm status --json		: display as json
+
+exit code is 1 if any opm process is not running or its http endpoint doesn't answer.
+opm process whose startmode is manual is not checked while it is not running
+`
+
+const (
+	endpointOk      = "OK"
+	endpointDown    = "DOWN"
+	endpointUnknown = "-"
+)
+
+type opmStatus struct {
+	Process    string `json:"process"`
+	PidFile    string `json:"pid_file"`
+	Pid        int    `json:"pid,omitempty"`
+	Status     string `json:"status"`
+	Uptime     string `json:"uptime,omitempty"`
+	AppLink    string `json:"app_link"`
+	LinkTarget string `json:"link_target,omitempty"`
+	Endpoint   string `json:"endpoint,omitempty"`
+	Http       string `json:"http"`
+	Message    string `json:"message,omitempty"`
+}
+
+// IsHealthy 프로세스가 살아있고 http endpoint 가 (확인 가능한 경우) 응답하는지 확인한다
+func (s opmStatus) IsHealthy() bool {
+	return s.Status == localproc.StatusRunning.String() && s.Http != endpointDown
+}
+
+type commandFlags struct {
+	Json    bool
+	Ports   string
+	Timeout time.Duration
+}
+
+func main() {
+	if len(os.Args) < 2 || strings.ToLower(os.Args[1]) != "status" {
+		fmt.Printf(usage, filepath.Base(os.Args[0]))
+		return
+	}
+
+	if len(os.Getenv(share.EnvFatimaHome)) == 0 {
+		fmt.Printf("env %s missing\n", share.EnvFatimaHome)
+		os.Exit(1)
+	}
+
+	flags := commandFlags{}
+	statusCommand := flag.NewFlagSet("status", flag.ExitOnError)
+	statusCommand.BoolVar(&flags.Json, "json", false, "print as json")
+	statusCommand.StringVar(&flags.Ports, "ports", "", "http port of opm process. e.g) jupiter:9190,juno:9180")
+	statusCommand.DurationVar(&flags.Timeout, "timeout", 2*time.Second, "http check timeout")
+	_ = statusCommand.Parse(os.Args[2:])
+
+	opmPrograms, err := config.LoadOpmPrograms(os.Getenv(share.EnvFatimaHome))
+	if err != nil && !flags.Json {
+		fmt.Printf("%s. use default opm programs\n", err.Error())
+	}
+
+	ports, err := opmPorts(opmPrograms, flags.Ports)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	healthy := true
+	statusList := make([]opmStatus, 0)
+	for _, program := range opmPrograms {
+		s := collectStatus(program.Name, ports[program.Name], flags.Timeout)
+		if affectsExitCode(program, s) {
+			healthy = healthy && s.IsHealthy()
+		}
+		statusList = append(statusList, s)
+	}
+
+	if flags.Json {
+		b, _ := json.MarshalIndent(statusList, "", "  ")
+		fmt.Printf("%s\n", b)
+	} else {
+		printStatus(statusList)
+	}
+
+	if !healthy {
+		os.Exit(1)
+	}
+}
+
+// opmPorts 프로그램별 http port 를 기본 port, fatima-package.yaml 의 port, --ports 순서로 덮어써서 결정한다
+func opmPorts(programs []config.ProcessItem, value string) (map[string]int, error) {
+	ports, err := localproc.ParsePorts(localproc.DefaultPorts)
+	if err != nil {
+		return nil, err
+	}
+	for _, program := range programs {
+		if program.Port > 0 {
+			ports[program.Name] = program.Port
+		}
+	}
+
+	override, err := localproc.ParsePorts(value)
+	if err != nil {
+		return nil, err
+	}
+	for name, port := range override {
+		ports[name] = port
+	}
+	return ports, nil
+}
+
+// affectsExitCode startmode 가 manual 인 프로그램은 실행중이 아니어도 비정상으로 보지 않는다
+func affectsExitCode(program config.ProcessItem, s opmStatus) bool {
+	return program.IsAutoStart() || s.Status != localproc.StatusNotRunning.String()
+}
+
+// collectStatus pid 파일, 프로세스 생존 여부, uptime, revision link, http 응답 여부를 수집한다
+func collectStatus(proc string, port int, timeout time.Duration) opmStatus {
+	s := opmStatus{
+		Process: proc,
+		PidFile: localproc.PidFile(proc),
+		AppLink: localproc.AppDir(proc),
+		Http:    endpointUnknown,
+	}
+
+	if target, err := os.Readlink(s.AppLink); err == nil {
+		s.LinkTarget = target
+	}
+
+	process, err := localproc.Lookup(proc)
+	if err != nil {
+		s.Status = "ERROR"
+		s.Message = err.Error()
+		return s
+	}
+	s.Pid = process.Pid
+	s.Status = process.Status.String()
+	if !process.IsRunning() {
+		return s
+	}
+
+	stat, err := localproc.Stat(process.Pid)
+	if err == nil {
+		s.Uptime = stat.Uptime().String()
+	}
+
+	if port == 0 {
+		return s
+	}
+
+	s.Endpoint = fmt.Sprintf("http://127.0.0.1:%d", port)
+	code, err := probeHttp(s.Endpoint, timeout)
+	if err != nil {
+		s.Http = endpointDown
+		s.Message = err.Error()
+		return s
+	}
+	s.Http = fmt.Sprintf("%s(%d)", endpointOk, code)
+	return s
+}
+
+// probeHttp endpoint 가 http 로 응답하는지 확인한다. 응답 코드와 상관없이 응답이 오면 성공으로 본다
+func probeHttp(endpoint string, timeout time.Duration) (int, error) {
+	client := http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func printStatus(statusList []opmStatus) {
+	data := make([][]string, 0)
+	messages := make([]string, 0)
+	for _, s := range statusList {
+		pid := ""
+		if s.Pid > 0 {
+			pid = strconv.Itoa(s.Pid)
+		}
+		link := s.LinkTarget
+		if len(link) == 0 {
+			link = endpointUnknown
+		}
+		endpoint := s.Endpoint
+		if len(endpoint) == 0 {
+			endpoint = endpointUnknown
+		}
+		data = append(data, []string{s.Process, pid, s.Status, s.Uptime, link, endpoint, s.Http})
+		if len(s.Message) > 0 {
+			messages = append(messages, fmt.Sprintf("%s : %s", s.Process, s.Message))
+		}
+	}
+	share.PrintTable([]string{"process", "pid", "status", "uptime", "revision", "endpoint", "http"}, data)
+
+	for _, m := range messages {
+		fmt.Printf("%s\n", m)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 12:40
+ */
+
+package main
+
+import (
+	"github.com/fatima-go/fatima-cmd/config"
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProbeHttp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	code, err := probeHttp(server.URL, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	server.Close()
+	_, err = probeHttp(server.URL, time.Second)
+	assert.NotNil(t, err)
+}
+
+func TestCollectStatusNotRunning(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+
+	s := collectStatus("juno", 0, time.Second)
+	assert.Equal(t, "NOT_RUNNING", s.Status)
+	assert.Equal(t, endpointUnknown, s.Http)
+	assert.False(t, s.IsHealthy())
+}
+
+func TestAffectsExitCode(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+
+	s := collectStatus("saturn", 0, time.Second)
+	assert.True(t, affectsExitCode(config.ProcessItem{Name: "saturn"}, s))
+	assert.False(t, affectsExitCode(config.ProcessItem{Name: "saturn", Startmode: config.StartModeManual}, s))
+}
+
+func TestOpmPorts(t *testing.T) {
+	programs := []config.ProcessItem{{Name: "jupiter"}, {Name: "juno", Port: 9180}, {Name: "saturn", Port: 9170}}
+
+	ports, err := opmPorts(programs, "")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"jupiter": 9190, "juno": 9180, "saturn": 9170}, ports)
+
+	// --ports 가 설정 파일의 port 보다 우선한다
+	ports, err = opmPorts(programs, " saturn:9171 , opmhelper:9200,")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"jupiter": 9190, "juno": 9180, "saturn": 9171, "opmhelper": 9200}, ports)
+
+	for _, value := range []string{"saturn", "saturn:", "saturn:abc", "saturn:-1", "saturn:0"} {
+		_, err = opmPorts(programs, value)
+		assert.NotNil(t, err, value)
+	}
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 9:30
+ */
+
+package main
+
+import (
+	"debug/buildinfo"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type moduleInfo struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+	Replace string `json:"replace,omitempty"`
+}
+
+type binaryBuildInfo struct {
+	Process   string       `json:"process"`
+	Revision  string       `json:"revision"`
+	Binary    string       `json:"binary"`
+	GoVersion string       `json:"go_version"`
+	Main      moduleInfo   `json:"main"`
+	VcsRev    string       `json:"vcs_revision,omitempty"`
+	VcsTime   string       `json:"vcs_time,omitempty"`
+	Modified  bool         `json:"vcs_modified,omitempty"`
+	Deps      []moduleInfo `json:"deps"`
+}
+
+// readBuildInfo go 바이너리에 포함된 빌드 정보(go 버전, 모듈, vcs)를 읽는다
+func readBuildInfo(binary string) (binaryBuildInfo, error) {
+	b := binaryBuildInfo{Binary: binary, Deps: make([]moduleInfo, 0)}
+	info, err := buildinfo.ReadFile(binary)
+	if err != nil {
+		return b, fmt.Errorf("fail to read build info of %s : %s", binary, err.Error())
+	}
+
+	b.GoVersion = info.GoVersion
+	b.Main = moduleInfo{Path: info.Main.Path, Version: info.Main.Version, Sum: info.Main.Sum}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.VcsRev = s.Value
+		case "vcs.time":
+			b.VcsTime = s.Value
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+
+	for _, d := range info.Deps {
+		m := moduleInfo{Path: d.Path, Version: d.Version, Sum: d.Sum}
+		if d.Replace != nil {
+			m.Replace = strings.TrimSpace(d.Replace.Path + " " + d.Replace.Version)
+		}
+		b.Deps = append(b.Deps, m)
+	}
+	return b, nil
+}
+
+// findBinary revision 디렉토리에서 프로세스 실행 파일을 찾는다
+func findBinary(dir, proc string) (string, error) {
+	binary := filepath.Join(dir, proc)
+	fi, err := os.Stat(binary)
+	if err != nil || !fi.Mode().IsRegular() {
+		return "", fmt.Errorf("not found executable %s", binary)
+	}
+	return binary, nil
+}
+
+func buildInfoOf(proc string, target Revision) (binaryBuildInfo, error) {
+	binary, err := findBinary(target.dir, proc)
+	if err != nil {
+		return binaryBuildInfo{}, err
+	}
+
+	b, err := readBuildInfo(binary)
+	b.Process = proc
+	b.Revision = target.revision
+	return b, err
+}
+
+func showBuildInfo() {
+	revFolder := getRevisionPath(proc)
+	if !isExistRevision(revFolder) {
+		fmt.Printf("%s revision folder doesn't exist\n", proc)
+		return
+	}
+
+	var target Revision
+	var ok bool
+	revisions := getRevisions(revFolder)
+	if len(os.Args) > 3 {
+		target, ok = getVersion(revisions, strings.ToUpper(os.Args[3]))
+	} else {
+		target, ok = findCurrentRevision(proc, revisions)
+	}
+	if !ok {
+		fmt.Printf("Not found revision\n")
+		return
+	}
+
+	b, err := buildInfoOf(proc, target)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("%-11s: %s %s\n", "process", b.Process, b.Revision)
+	fmt.Printf("%-11s: %s\n", "binary", b.Binary)
+	fmt.Printf("%-11s: %s\n", "go version", b.GoVersion)
+	fmt.Printf("%-11s: %s %s\n", "main", b.Main.Path, b.Main.Version)
+	if len(b.VcsRev) > 0 {
+		modified := ""
+		if b.Modified {
+			modified = " (modified)"
+		}
+		fmt.Printf("%-11s: %s %s%s\n", "vcs", b.VcsRev, b.VcsTime, modified)
+	}
+	fmt.Printf("dependencies (%d)\n", len(b.Deps))
+	for _, d := range b.Deps {
+		if len(d.Replace) > 0 {
+			fmt.Printf("  %s %s => %s\n", d.Path, d.Version, d.Replace)
+		} else {
+			fmt.Printf("  %s %s\n", d.Path, d.Version)
+		}
+	}
+}
+
+// buildInfoAll 모든 프로세스의 현재 revision 빌드 정보를 csv/json 으로 출력한다
+func buildInfoAll() {
+	buildInfoCommand := flag.NewFlagSet("buildinfo", flag.ExitOnError)
+	var format string
+	buildInfoCommand.StringVar(&format, "format", "csv", "output format. csv or json")
+	_ = buildInfoCommand.Parse(os.Args[3:])
+
+	procs, err := listRevisionProcs(false)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	list := make([]binaryBuildInfo, 0)
+	for _, p := range procs {
+		target, ok := findCurrentRevision(p, getRevisions(getRevisionPath(p)))
+		if !ok {
+			continue
+		}
+		b, err := buildInfoOf(p, target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s : %s\n", p, err.Error())
+			continue
+		}
+		list = append(list, b)
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		data, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Printf("%s\n", data)
+	case "csv":
+		writeBuildInfoCsv(list)
+	default:
+		fmt.Printf("unsupported format : %s\n", format)
+		os.Exit(1)
+	}
+}
+
+// writeBuildInfoCsv 프로세스의 모듈(main 포함) 하나를 한 줄로 출력한다
+func writeBuildInfoCsv(list []binaryBuildInfo) {
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write([]string{"process", "revision", "go_version", "vcs_revision", "vcs_time", "type", "module", "version", "sum", "replace"})
+	for _, b := range list {
+		prefix := []string{b.Process, b.Revision, b.GoVersion, b.VcsRev, b.VcsTime}
+		_ = w.Write(append(prefix, "main", b.Main.Path, b.Main.Version, b.Main.Sum, ""))
+		for _, d := range b.Deps {
+			_ = w.Write(append(prefix, "dep", d.Path, d.Version, d.Sum, d.Replace))
+		}
+	}
+	w.Flush()
+}
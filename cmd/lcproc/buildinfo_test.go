@@ -0,0 +1,43 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 9:30
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestReadBuildInfo(t *testing.T) {
+	exe, err := os.Executable()
+	assert.Nil(t, err)
+
+	b, err := readBuildInfo(exe)
+	assert.Nil(t, err)
+	assert.Equal(t, runtime.Version(), b.GoVersion)
+
+	script := filepath.Join(t.TempDir(), "sample.sh")
+	assert.Nil(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0755))
+	_, err = readBuildInfo(script)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 10:00
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/revision"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"strconv"
+)
+
+func buildDedupeDryRun() bool {
+	dedupeCommand := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	var dryRun bool
+	dedupeCommand.BoolVar(&dryRun, "dry-run", false, "display only reclaimable size")
+	_ = dedupeCommand.Parse(os.Args[3:])
+	return dryRun
+}
+
+// dedupeRevision 프로세스의 revision 들 사이에서 같은 파일을 hardlink 로 합친다
+func dedupeRevision() {
+	dryRun := buildDedupeDryRun()
+
+	revFolder := getRevisionPath(proc)
+	if !isExistRevision(revFolder) {
+		fmt.Printf("%s revision folder doesn't exist\n", proc)
+		return
+	}
+
+	result, err := revision.Dedupe(revFolder, dryRun)
+	if err != nil {
+		fmt.Printf("fail to dedupe %s : %s\n", proc, err.Error())
+		os.Exit(1)
+	}
+
+	printDedupeResult(proc, result, dryRun)
+}
+
+func dedupeAll() {
+	dryRun := buildDedupeDryRun()
+
+	procs, err := listRevisionProcs(false)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	failed := false
+	total := revision.DedupeResult{}
+	data := make([][]string, 0)
+	for _, p := range procs {
+		result, err := revision.Dedupe(getRevisionPath(p), dryRun)
+		if err != nil {
+			fmt.Printf("fail to dedupe %s : %s\n", p, err.Error())
+			failed = true
+		}
+		total.Files += result.Files
+		total.Linked += result.Linked
+		total.Reclaimed += result.Reclaimed
+		data = append(data, []string{p, strconv.Itoa(result.Files), strconv.Itoa(result.Linked), humanSize(result.Reclaimed)})
+	}
+	data = append(data, []string{"total", strconv.Itoa(total.Files), strconv.Itoa(total.Linked), humanSize(total.Reclaimed)})
+	share.PrintTable([]string{"process", "files", "linked", "reclaimed"}, data)
+
+	if dryRun {
+		fmt.Printf("dry run. nothing changed\n")
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func printDedupeResult(proc string, result revision.DedupeResult, dryRun bool) {
+	if dryRun {
+		fmt.Printf("%s : %d of %d files can be linked. %s reclaimable\n", proc, result.Linked, result.Files, humanSize(result.Reclaimed))
+		return
+	}
+	fmt.Printf("%s : %d of %d files linked. %s reclaimed\n", proc, result.Linked, result.Files, humanSize(result.Reclaimed))
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 5:05
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/revision"
+	"github.com/pmezard/go-difflib/difflib"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func diffRevision() {
+	diffCommand := flag.NewFlagSet("diff", flag.ExitOnError)
+	var stat bool
+	diffCommand.BoolVar(&stat, "stat", false, "display only count of changed files")
+	args := parseArgs(diffCommand, os.Args[3:])
+	if len(args) != 2 {
+		fmt.Printf(usage, os.Args[0])
+		return
+	}
+
+	revFolder := getRevisionPath(proc)
+	if !isExistRevision(revFolder) {
+		fmt.Printf("%s revision folder doesn't exist\n", proc)
+		return
+	}
+
+	revisions := getRevisions(revFolder)
+	from, ok := getVersion(revisions, strings.ToUpper(args[0]))
+	if !ok {
+		fmt.Printf("Not found revision %s\n", args[0])
+		return
+	}
+	to, ok := getVersion(revisions, strings.ToUpper(args[1]))
+	if !ok {
+		fmt.Printf("Not found revision %s\n", args[1])
+		return
+	}
+
+	err := writeDiff(os.Stdout, from, to, stat)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+	}
+}
+
+// writeDiff from, to revision 사이에 추가, 삭제, 변경된 파일을 출력한다. 변경된 설정 파일은 unified diff 도 출력한다
+func writeDiff(w io.Writer, from, to Revision, stat bool) error {
+	fromTree, err := revision.HashTree(from.dir)
+	if err != nil {
+		return fmt.Errorf("fail to read %s : %s", from.dir, err.Error())
+	}
+	toTree, err := revision.HashTree(to.dir)
+	if err != nil {
+		return fmt.Errorf("fail to read %s : %s", to.dir, err.Error())
+	}
+
+	d := revision.Compare(fromTree, toTree)
+	fmt.Fprintf(w, "%s %s -> %s\n", proc, from.revision, to.revision)
+	if stat {
+		fmt.Fprintf(w, "added %d, removed %d, changed %d\n", len(d.Added), len(d.Removed), len(d.Changed))
+		return nil
+	}
+
+	if d.IsEmpty() {
+		fmt.Fprintf(w, "there is no difference\n")
+		return nil
+	}
+
+	printFileList(w, "added", "+", d.Added)
+	printFileList(w, "removed", "-", d.Removed)
+	printFileList(w, "changed", "*", d.Changed)
+	printDeploymentDiff(w, from, to)
+
+	for _, name := range d.Changed {
+		if !revision.IsConfigFile(name) {
+			continue
+		}
+		printUnifiedDiff(w, from, to, name)
+	}
+	return nil
+}
+
+func printFileList(w io.Writer, title, mark string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s (%d)\n", title, len(files))
+	for _, f := range files {
+		fmt.Fprintf(w, "  %s %s\n", mark, f)
+	}
+}
+
+func printUnifiedDiff(w io.Writer, from, to Revision, name string) {
+	a, err := os.ReadFile(filepath.Join(from.dir, name))
+	if err != nil {
+		fmt.Fprintf(w, "fail to read %s : %s\n", name, err.Error())
+		return
+	}
+	b, err := os.ReadFile(filepath.Join(to.dir, name))
+	if err != nil {
+		fmt.Fprintf(w, "fail to read %s : %s\n", name, err.Error())
+		return
+	}
+
+	text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(a)),
+		B:        difflib.SplitLines(string(b)),
+		FromFile: filepath.ToSlash(filepath.Join(from.revision, name)),
+		ToFile:   filepath.ToSlash(filepath.Join(to.revision, name)),
+		Context:  3,
+	})
+	if err != nil {
+		fmt.Fprintf(w, "fail to diff %s : %s\n", name, err.Error())
+		return
+	}
+	fmt.Fprintf(w, "\n%s", text)
+}
+
+// printDeploymentDiff deployment.json 의 build/git 정보를 비교한다
+func printDeploymentDiff(w io.Writer, from, to Revision) {
+	fromDeployment, fromErr := readDeployment(from.dir)
+	toDeployment, toErr := readDeployment(to.dir)
+	if fromErr != nil || toErr != nil {
+		return
+	}
+
+	fields := [][]string{
+		{"build.time", fromDeployment.Build.BuildTime, toDeployment.Build.BuildTime},
+		{"build.user", fromDeployment.Build.BuildUser, toDeployment.Build.BuildUser},
+		{"git.branch", fromDeployment.Build.Git.Branch, toDeployment.Build.Git.Branch},
+		{"git.commit", fromDeployment.Build.Git.Commit, toDeployment.Build.Git.Commit},
+		{"git.message", GetTrimmedMessage(fromDeployment.Build.Git.Message), GetTrimmedMessage(toDeployment.Build.Git.Message)},
+	}
+
+	changed := make([][]string, 0)
+	for _, f := range fields {
+		if f[1] != f[2] {
+			changed = append(changed, f)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%s\n", deploymentJsonFile)
+	for _, f := range changed {
+		fmt.Fprintf(w, "  %-12s: %s -> %s\n", f[0], f[1], f[2])
+	}
+}
+
+func readDeployment(dir string) (Deployment, error) {
+	deployment := Deployment{}
+	file, err := os.ReadFile(filepath.Join(dir, deploymentJsonFile))
+	if err != nil {
+		return deployment, err
+	}
+
+	err = json.Unmarshal(file, &deployment)
+	return deployment, err
+}
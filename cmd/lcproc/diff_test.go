@@ -0,0 +1,126 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오후 4:40
+ */
+
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeRevisionFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestWriteDiff(t *testing.T) {
+	proc = "sample"
+	tests := []struct {
+		name        string
+		from        map[string]string
+		to          map[string]string
+		stat        bool
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "no difference",
+			from:     map[string]string{"sample.yaml": "port: 9190\n"},
+			to:       map[string]string{"sample.yaml": "port: 9190\n"},
+			contains: []string{"sample R001 -> R002\n", "there is no difference\n"},
+		},
+		{
+			name:        "added",
+			from:        map[string]string{"sample.yaml": "port: 9190\n"},
+			to:          map[string]string{"sample.yaml": "port: 9190\n", "patch.yaml": "a: 1\n"},
+			contains:    []string{"added (1)\n  + patch.yaml\n"},
+			notContains: []string{"removed", "changed", "---"},
+		},
+		{
+			name:        "removed",
+			from:        map[string]string{"sample.yaml": "port: 9190\n", "old.properties": "a=1\n"},
+			to:          map[string]string{"sample.yaml": "port: 9190\n"},
+			contains:    []string{"removed (1)\n  - old.properties\n"},
+			notContains: []string{"added", "changed", "---"},
+		},
+		{
+			name:     "modified text",
+			from:     map[string]string{"sample.yaml": "name: sample\nport: 9190\n"},
+			to:       map[string]string{"sample.yaml": "name: sample\nport: 9191\n"},
+			contains: []string{"changed (1)\n  * sample.yaml\n", "--- R001/sample.yaml\n+++ R002/sample.yaml\n", "-port: 9190\n+port: 9191\n"},
+		},
+		{
+			name:        "modified binary",
+			from:        map[string]string{"sample": "\x7fELF\x00\x01"},
+			to:          map[string]string{"sample": "\x7fELF\x00\x02"},
+			contains:    []string{"changed (1)\n  * sample\n"},
+			notContains: []string{"---", "+++", "ELF"},
+		},
+		{
+			name:        "stat",
+			from:        map[string]string{"sample.yaml": "port: 9190\n", "old.properties": "a=1\n"},
+			to:          map[string]string{"sample.yaml": "port: 9191\n", "patch.yaml": "a: 1\n"},
+			stat:        true,
+			contains:    []string{"added 1, removed 1, changed 1\n"},
+			notContains: []string{"patch.yaml", "---"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := Revision{revision: "R001", dir: writeRevisionFiles(t, tt.from)}
+			to := Revision{revision: "R002", dir: writeRevisionFiles(t, tt.to)}
+
+			var buf bytes.Buffer
+			assert.Nil(t, writeDiff(&buf, from, to, tt.stat))
+			for _, s := range tt.contains {
+				assert.Contains(t, buf.String(), s)
+			}
+			for _, s := range tt.notContains {
+				assert.NotContains(t, buf.String(), s)
+			}
+		})
+	}
+}
+
+func TestWriteDiffDeployment(t *testing.T) {
+	proc = "sample"
+	from := Revision{revision: "R001", dir: writeRevisionFiles(t, map[string]string{
+		deploymentJsonFile: `{"process":"sample","build":{"user":"user1","git":{"branch":"main","commit":"aaaa"}}}`,
+	})}
+	to := Revision{revision: "R002", dir: writeRevisionFiles(t, map[string]string{
+		deploymentJsonFile: `{"process":"sample","build":{"user":"user2","git":{"branch":"main","commit":"bbbb"}}}`,
+	})}
+
+	var buf bytes.Buffer
+	assert.Nil(t, writeDiff(&buf, from, to, false))
+	assert.Contains(t, buf.String(), "  build.user  : user1 -> user2\n")
+	assert.Contains(t, buf.String(), "  git.commit  : aaaa -> bbbb\n")
+	assert.NotContains(t, buf.String(), "git.branch")
+	assert.Contains(t, buf.String(), "--- R001/deployment.json\n")
+
+	assert.NotNil(t, writeDiff(&buf, Revision{revision: "R003", dir: filepath.Join(t.TempDir(), "none")}, to, false))
+}
@@ -21,9 +21,11 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"github.com/fatima-go/fatima-cmd/juno"
+	"github.com/fatima-go/fatima-cmd/revision"
 	"github.com/fatima-go/fatima-cmd/share"
-	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -31,10 +33,32 @@ import (
 	"time"
 )
 
+// default process group if user not specify
+const defaultGroupValue = "4"
+
 var targetProc string
 
+type dupFlags struct {
+	Sets     keyValueList
+	Register bool
+	Group    string
+	Yes      bool
+}
+
 func duplicate() {
-	targetProc = os.Args[3]
+	flags := dupFlags{}
+	dupCommand := flag.NewFlagSet("dup", flag.ExitOnError)
+	dupCommand.Var(&flags.Sets, "set", "override config value. key=value (repeatable). yaml key is full path e.g) web.port")
+	dupCommand.BoolVar(&flags.Register, "register", false, "register new process to package like 'roproc add'")
+	dupCommand.StringVar(&flags.Group, "group", defaultGroupValue, "process group id for register")
+	dupCommand.BoolVar(&flags.Yes, "yes", false, "do not ask confirmation for --set")
+	args := parseArgs(dupCommand, os.Args[3:])
+	if len(args) != 1 {
+		fmt.Printf(usage, os.Args[0])
+		return
+	}
+
+	targetProc = args[0]
 	if !isAppExist(proc) {
 		fmt.Printf("%s process doesn't exist\n", proc)
 		return
@@ -45,14 +69,32 @@ func duplicate() {
 		return
 	}
 
-	revision, err := createRevisionApp(targetProc)
+	sourceFiles, err := collectSourceFiles()
+	if err != nil {
+		fmt.Printf("fail to duplicate process for %s : %s\n", proc, err.Error())
+		return
+	}
+
+	rewrites, err := buildRewrites(sourceFiles, flags.Sets)
+	if err != nil {
+		fmt.Printf("fail to read config : %s\n", err.Error())
+		return
+	}
+
+	printRewritePreview(sourceFiles, rewrites)
+	// --set 으로 설정값을 변경하는 경우에만 치환 내용을 확인받는다
+	if len(flags.Sets) > 0 && !flags.Yes && !share.AskYes(fmt.Sprintf("duplicate %s to %s?", proc, targetProc)) {
+		return
+	}
+
+	rev, err := createRevisionApp(targetProc)
 	if err != nil {
 		fmt.Printf("fail to create process for %s : %s\n", targetProc, err.Error())
 		return
 	}
 
-	fmt.Printf("targetPath : %s\n", revision.dir)
-	err = copyToDest(revision.dir)
+	fmt.Printf("targetPath : %s\n", rev.dir)
+	err = copyToDest(sourceFiles, rewrites, rev.dir)
 	if err != nil {
 		fmt.Printf("fail to duplicate process for %s : %s\n", proc, err.Error())
 		return
@@ -60,13 +102,42 @@ func duplicate() {
 
 	fmt.Printf("successfully duplicated %s to %s\n", proc, targetProc)
 
-	err = linkRevision(targetProc, revision)
+	_, err = revision.WriteManifest(rev.dir)
+	if err != nil {
+		fmt.Printf("fail to write manifest : %s\n", err.Error())
+	}
+
+	_, err = linkRevision(targetProc, rev)
 	if err != nil {
 		fmt.Printf("fail to link revision for %s : %s\n", targetProc, err.Error())
 		return
 	}
 
-	fmt.Printf("\nyou have to add process in config using roproc command\n")
+	if !flags.Register {
+		fmt.Printf("\nyou have to add process in config using roproc command\n")
+		return
+	}
+
+	err = registerProcess(targetProc, flags.Group)
+	if err != nil {
+		fmt.Printf("fail to register %s : %s\n", targetProc, err.Error())
+		fmt.Printf("you have to add process in config using roproc command\n")
+	}
+}
+
+// registerProcess 'roproc add' 와 동일하게 juno 에 프로세스를 등록한다
+func registerProcess(procName string, group string) error {
+	fatimaFlags, err := share.BuildFatimaCmdFlags()
+	if err != nil {
+		return err
+	}
+
+	err = share.GetJunoEndpoint(&fatimaFlags)
+	if err != nil {
+		return fmt.Errorf("endpoint retrieve fail : %s", err.Error())
+	}
+
+	return juno.AddJunoProc(fatimaFlags, procName, group)
 }
 
 func isAppExist(proc string) bool {
@@ -100,11 +171,11 @@ func createRevisionApp(proc string) (Revision, error) {
 
 var suffixList = [...]string{"properties", "xml", "json", "yaml", "sh", "yml", "dat", "p8", "rb", "rbw", "lua"}
 
-func copyToDest(targetPath string) error {
+func collectSourceFiles() ([]string, error) {
 	appLink := filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderApp, proc)
 	files, err := ioutil.ReadDir(appLink)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	sourceFiles := make([]string, 0)
@@ -131,11 +202,14 @@ func copyToDest(targetPath string) error {
 	}
 
 	if len(sourceFiles) == 0 {
-		return fmt.Errorf("there is no source files...")
+		return nil, fmt.Errorf("there is no source files...")
 	}
+	return sourceFiles, nil
+}
 
+func copyToDest(sourceFiles []string, rewrites map[string]configRewrite, targetPath string) error {
 	for _, s := range sourceFiles {
-		err := transferFile(s, targetPath)
+		err := transferFile(s, rewrites, targetPath)
 		if err != nil {
 			return fmt.Errorf("copy fail [%s -> %s] : %s", s, targetPath, err.Error())
 		}
@@ -145,7 +219,7 @@ func copyToDest(targetPath string) error {
 	return nil
 }
 
-func transferFile(srcFile string, targetPath string) error {
+func transferFile(srcFile string, rewrites map[string]configRewrite, targetPath string) error {
 	fileName := filepath.Base(srcFile)
 	resolved := filepath.Join(targetPath, fileName)
 	if strings.HasPrefix(fileName, proc) {
@@ -154,28 +228,25 @@ func transferFile(srcFile string, targetPath string) error {
 	}
 
 	fmt.Printf("copying to %s\n", resolved)
+	if r, ok := rewrites[srcFile]; ok && len(r.subs) > 0 {
+		return writeRewrite(srcFile, r, resolved)
+	}
 	return copyFile(srcFile, resolved)
 }
 
-func copyFile(srcFile, dstFile string) error {
-	from, err := os.Open(srcFile)
-	if err != nil {
-		return err
-	}
-	defer from.Close()
-	stat, _ := from.Stat()
-	to, err := os.OpenFile(dstFile, os.O_RDWR|os.O_CREATE, stat.Mode())
-	if err != nil {
-		return err
-	}
-	defer to.Close()
-
-	_, err = io.Copy(to, from)
+// writeRewrite 치환된 내용을 원본 파일과 같은 권한으로 저장한다.
+// dstFile 이 hardlink 일 수 있으므로 덮어쓰지 않고 새 파일로 교체한다
+func writeRewrite(srcFile string, r configRewrite, dstFile string) error {
+	stat, err := os.Stat(srcFile)
 	if err != nil {
 		return err
 	}
+	return revision.WriteFile(dstFile, r.content, stat.Mode().Perm())
+}
 
-	return nil
+// copyFile dstFile 이 hardlink 일 수 있으므로 덮어쓰지 않고 새 파일로 교체한다
+func copyFile(srcFile, dstFile string) error {
+	return revision.CopyFile(srcFile, dstFile)
 }
 
 const TIME_YYYYMMDDHHMMSS = "2006.01.02-15.04"
@@ -0,0 +1,267 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 8:00
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/revision"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// keyValue --set key=value 로 지정한 설정값
+type keyValue struct {
+	key   string
+	value string
+}
+
+// keyValueList 여러번 지정할 수 있는 --set flag
+type keyValueList []keyValue
+
+func (l *keyValueList) String() string {
+	list := make([]string, 0)
+	for _, kv := range *l {
+		list = append(list, kv.key+"="+kv.value)
+	}
+	return strings.Join(list, ",")
+}
+
+func (l *keyValueList) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || len(key) == 0 {
+		return fmt.Errorf("invalid key=value : %s", s)
+	}
+	*l = append(*l, keyValue{key: key, value: strings.TrimSpace(value)})
+	return nil
+}
+
+type substitution struct {
+	line   int
+	before string
+	after  string
+}
+
+// configRewrite 복사할 설정 파일의 변경 내용
+type configRewrite struct {
+	file    string
+	content []byte
+	subs    []substitution
+	keys    map[string]bool // 적용된 --set key
+}
+
+// yamlKeyPath yaml 라인의 들여쓰기로 상위 key 를 추적한다
+type yamlKeyPath struct {
+	indents []int
+	keys    []string
+}
+
+// next line 의 전체 key 경로(e.g. web.port)를 리턴한다. key 가 없는 라인이면 빈 문자열을 리턴한다
+func (p *yamlKeyPath) next(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	body := strings.TrimSpace(trimmed)
+	if len(body) == 0 || strings.HasPrefix(body, "#") {
+		return ""
+	}
+	if body == "---" {
+		p.indents, p.keys = nil, nil
+		return ""
+	}
+
+	indent := len(line) - len(trimmed)
+	for len(p.indents) > 0 && p.indents[len(p.indents)-1] >= indent {
+		p.indents = p.indents[:len(p.indents)-1]
+		p.keys = p.keys[:len(p.keys)-1]
+	}
+
+	key, _, ok := strings.Cut(body, ":")
+	if !ok || strings.HasPrefix(body, "-") {
+		// list item 은 key 경로를 지원하지 않는다
+		return ""
+	}
+
+	p.indents = append(p.indents, indent)
+	p.keys = append(p.keys, strings.TrimSpace(key))
+	return strings.Join(p.keys, ".")
+}
+
+// rewriteConfig 설정 파일 안의 from 프로세스 이름을 to 로 치환하고 --set 값을 적용한다
+func rewriteConfig(file string, from, to string, sets keyValueList) (configRewrite, error) {
+	r := configRewrite{file: file, subs: make([]substitution, 0), keys: make(map[string]bool)}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return r, err
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(file), ".")
+	keyPath := yamlKeyPath{}
+	lines := strings.SplitAfter(string(data), "\n")
+	for i, line := range lines {
+		replaced := replaceName(line, from, to)
+		path := keyPath.next(replaced)
+		for _, kv := range sets {
+			if v, ok := setValue(replaced, ext, path, kv); ok {
+				replaced = v
+				r.keys[kv.key] = true
+			}
+		}
+		if replaced != line {
+			r.subs = append(r.subs, substitution{line: i + 1, before: line, after: replaced})
+			lines[i] = replaced
+		}
+	}
+
+	r.content = []byte(strings.Join(lines, ""))
+	return r, nil
+}
+
+// replaceName 영문자나 숫자에 붙어있지 않은 name 만 치환한다. mypgm 을 치환할 때 mypgm2 는 바꾸지 않는다
+func replaceName(line, from, to string) string {
+	var sb strings.Builder
+	rest := line
+	for {
+		idx := strings.Index(rest, from)
+		if idx < 0 {
+			sb.WriteString(rest)
+			return sb.String()
+		}
+
+		end := idx + len(from)
+		if isAlnumAt(rest, idx-1) || isAlnumAt(rest, end) {
+			sb.WriteString(rest[:end])
+		} else {
+			sb.WriteString(rest[:idx])
+			sb.WriteString(to)
+		}
+		rest = rest[end:]
+	}
+}
+
+func isAlnumAt(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// setValue yaml 의 'key: value', properties 의 'key=value' 라인이면 값을 변경한다.
+// yaml 은 path(상위 key 를 포함한 전체 경로)가 같아야 하며 라인 끝의 주석은 유지한다
+func setValue(line, ext, path string, kv keyValue) (string, bool) {
+	sep := ""
+	switch ext {
+	case "yaml", "yml":
+		if path != kv.key {
+			return line, false
+		}
+		sep = ":"
+	case "properties":
+		sep = "="
+	default:
+		return line, false
+	}
+
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+	key, rest, ok := strings.Cut(trimmed, sep)
+	if !ok {
+		return line, false
+	}
+	key = strings.TrimSpace(key)
+	if sep == "=" && key != kv.key {
+		return line, false
+	}
+
+	value := strings.TrimRight(rest, "\r\n")
+	newline := rest[len(value):]
+	comment := ""
+	if sep == ":" {
+		if idx := yamlCommentIndex(value); idx >= 0 {
+			comment = value[idx:]
+		}
+		return fmt.Sprintf("%s%s: %s%s%s", indent, key, kv.value, comment, newline), true
+	}
+	return fmt.Sprintf("%s%s=%s%s", indent, key, kv.value, newline), true
+}
+
+// yamlCommentIndex 값 뒤의 ' #' 주석 시작 위치(공백 포함)를 리턴한다. 따옴표 안의 # 은 무시한다
+func yamlCommentIndex(value string) int {
+	var quote rune
+	for i, c := range value {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && i > 0 && (value[i-1] == ' ' || value[i-1] == '\t'):
+			start := i
+			for start > 0 && (value[start-1] == ' ' || value[start-1] == '\t') {
+				start--
+			}
+			return start
+		}
+	}
+	return -1
+}
+
+// buildRewrites 설정 파일의 치환 내용을 만들고 어떤 파일에도 적용되지 않은 --set key 를 알려준다
+func buildRewrites(sourceFiles []string, sets keyValueList) (map[string]configRewrite, error) {
+	rewrites := make(map[string]configRewrite)
+	applied := make(map[string]bool)
+	for _, f := range sourceFiles {
+		if !revision.IsConfigFile(f) {
+			continue
+		}
+
+		r, err := rewriteConfig(f, proc, targetProc, sets)
+		if err != nil {
+			return nil, err
+		}
+		rewrites[f] = r
+		for k := range r.keys {
+			applied[k] = true
+		}
+	}
+
+	for _, kv := range sets {
+		if !applied[kv.key] {
+			fmt.Printf("warning : not found key %s in config files\n", kv.key)
+		}
+	}
+	return rewrites, nil
+}
+
+func printRewritePreview(sourceFiles []string, rewrites map[string]configRewrite) {
+	for _, f := range sourceFiles {
+		r, ok := rewrites[f]
+		if !ok || len(r.subs) == 0 {
+			continue
+		}
+		fmt.Printf("%s\n", filepath.Base(r.file))
+		for _, s := range r.subs {
+			fmt.Printf("  %4d - %s\n", s.line, strings.TrimRight(s.before, "\r\n"))
+			fmt.Printf("  %4d + %s\n", s.line, strings.TrimRight(s.after, "\r\n"))
+		}
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 8:00
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceName(t *testing.T) {
+	assert.Equal(t, "log: /log/newpgm/newpgm.log", replaceName("log: /log/mypgm/mypgm.log", "mypgm", "newpgm"))
+	assert.Equal(t, "name: mypgm2", replaceName("name: mypgm2", "mypgm", "newpgm"))
+	assert.Equal(t, "id=newpgm_01", replaceName("id=mypgm_01", "mypgm", "newpgm"))
+}
+
+func TestRewriteConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "mypgm.yaml")
+	content := "process: mypgm\nweb:\n  port: 9190 # web port\nother: mypgm2\nadmin:\n  port: 9290\n"
+	assert.Nil(t, os.WriteFile(file, []byte(content), 0644))
+
+	sets := keyValueList{}
+	assert.Nil(t, sets.Set("web.port=9191"))
+	assert.NotNil(t, sets.Set("=1"))
+
+	r, err := rewriteConfig(file, "mypgm", "newpgm", sets)
+	assert.Nil(t, err)
+	// 다른 상위 key 의 port 는 바꾸지 않는다
+	assert.Equal(t, "process: newpgm\nweb:\n  port: 9191 # web port\nother: mypgm2\nadmin:\n  port: 9290\n", string(r.content))
+	assert.Equal(t, 2, len(r.subs))
+	assert.Equal(t, 3, r.subs[1].line)
+	assert.True(t, r.keys["web.port"])
+}
+
+func TestYamlKeyPath(t *testing.T) {
+	p := yamlKeyPath{}
+	assert.Equal(t, "web", p.next("web:\n"))
+	assert.Equal(t, "", p.next("  # comment\n"))
+	assert.Equal(t, "web.http", p.next("  http:\n"))
+	assert.Equal(t, "web.http.port", p.next("    port: 9190\n"))
+	assert.Equal(t, "web.timeout", p.next("  timeout: 3s\n"))
+	assert.Equal(t, "port", p.next("port: 9180\n"))
+
+	v, ok := setValue("url: \"http://a#b\" # url\n", "yaml", "url", keyValue{key: "url", value: "x"})
+	assert.True(t, ok)
+	assert.Equal(t, "url: x # url\n", v)
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 7:30
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/extract"
+	"github.com/fatima-go/fatima-cmd/revision"
+	"github.com/fatima-go/fatima-cmd/share"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func exportRevision() {
+	exportCommand := flag.NewFlagSet("export", flag.ExitOnError)
+	var output string
+	exportCommand.StringVar(&output, "o", "", "output file. default is <proc>_<revision>.tar.gz")
+	args := parseArgs(exportCommand, os.Args[3:])
+	if len(args) != 1 {
+		fmt.Printf(usage, os.Args[0])
+		return
+	}
+
+	revFolder := getRevisionPath(proc)
+	if !isExistRevision(revFolder) {
+		fmt.Printf("%s revision folder doesn't exist\n", proc)
+		return
+	}
+
+	target, ok := getVersion(getRevisions(revFolder), strings.ToUpper(args[0]))
+	if !ok {
+		fmt.Printf("Not found revision %s\n", args[0])
+		return
+	}
+
+	if len(output) == 0 {
+		output = fmt.Sprintf("%s_%s.tar.gz", proc, target.revision)
+	}
+
+	err := writeTarGz(target.dir, output)
+	if err != nil {
+		fmt.Printf("fail to export %s : %s\n", target.revision, err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s %s exported to %s\n", proc, target.revision, output)
+}
+
+// writeTarGz dir 하위의 파일을 권한, 수정시간, symlink 를 유지한 채로 tar.gz 로 묶는다.
+// pid 파일, 로그 같은 runtime 파일은 제외한다. 실패하면 생성중이던 output 을 삭제하며 이미 있는 파일은 덮어쓰지 않는다
+func writeTarGz(dir, output string) (err error) {
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		if err != nil {
+			_ = os.Remove(output)
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			header.Name += "/"
+		}
+
+		runtime := revision.IsRuntimePath(filepath.ToSlash(rel))
+		if runtime && !d.IsDir() {
+			return nil
+		}
+
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+		if runtime {
+			// proc, log 폴더는 빈 폴더로만 묶는다
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+	err = gz.Close()
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// importRevision export 된 revision 을 다음 revision 번호로 설치한다. 설치된 revision 으로 변경하지는 않는다
+func importRevision() {
+	if len(os.Args) < 4 {
+		fmt.Printf(usage, os.Args[0])
+		return
+	}
+
+	archive := os.Args[3]
+	revFolder := getRevisionPath(proc)
+	err := os.MkdirAll(revFolder, 0755)
+	if err != nil {
+		fmt.Printf("fail to create revision folder : %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	next := 1
+	revisions := getRevisions(revFolder)
+	if len(revisions) > 0 {
+		// revisions 는 number 의 역순으로 정렬되어 있다
+		next = revisions[0].number + 1
+	}
+
+	tempDir, err := os.MkdirTemp(revFolder, ".import-")
+	if err != nil {
+		fmt.Printf("fail to create temp dir : %s\n", err.Error())
+		os.Exit(1)
+	}
+	defer os.RemoveAll(tempDir)
+
+	opt := extract.DefaultOptions()
+	opt.Symlink = extract.SymlinkConfine
+	err = extract.TarGz(archive, tempDir, opt)
+	if err != nil {
+		fmt.Printf("fail to extract %s : %s\n", archive, err.Error())
+		os.Exit(1)
+	}
+
+	err = removeRuntimeFiles(tempDir)
+	if err != nil {
+		fmt.Printf("fail to clean %s : %s\n", archive, err.Error())
+		os.Exit(1)
+	}
+
+	err = validateImport(tempDir)
+	if err != nil {
+		fmt.Printf("invalid revision archive %s : %s\n", archive, err.Error())
+		os.Exit(1)
+	}
+
+	err = os.Chmod(tempDir, 0755)
+	if err != nil {
+		fmt.Printf("fail to chmod : %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	tag := fmt.Sprintf("%s_R%03d", time.Now().Format(TIME_YYYYMMDDHHMMSS), next)
+	err = os.Rename(tempDir, filepath.Join(revFolder, tag))
+	if err != nil {
+		fmt.Printf("fail to install revision : %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s imported as R%03d (%s)\n", archive, next, tag)
+	fmt.Printf("you can change revision using 'lcproc %s version R%03d'\n", proc, next)
+}
+
+// removeRuntimeFiles 이전 버전에서 export 한 archive 에 포함된 pid 파일, 로그를 삭제한다
+func removeRuntimeFiles(dir string) error {
+	for _, name := range []string{share.FatimaFolderAppProc, share.FatimaFolderLog} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		err := os.RemoveAll(path)
+		if err != nil {
+			return err
+		}
+		err = os.Mkdir(path, 0755)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateImport deployment.json 의 프로세스 이름과 export 당시의 manifest 를 검사한다. proc, log 폴더는 검사하지 않는다.
+// manifest 가 없으면 현재 파일로 baseline manifest 를 생성한다
+func validateImport(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, deploymentJsonFile))
+	if err != nil {
+		return fmt.Errorf("not found %s", deploymentJsonFile)
+	}
+
+	deployment := Deployment{}
+	err = json.Unmarshal(data, &deployment)
+	if err != nil {
+		return fmt.Errorf("fail to parse %s : %s", deploymentJsonFile, err.Error())
+	}
+	if len(deployment.Process) > 0 && deployment.Process != proc {
+		fmt.Printf("warning : archive is exported from process %s\n", deployment.Process)
+	}
+
+	d, err := revision.Verify(dir)
+	if errors.Is(err, revision.ErrNoManifest) {
+		// manifest 가 없는 archive 는 import 할 때의 파일을 baseline 으로 사용한다
+		_, err = revision.WriteManifest(dir)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if !d.IsEmpty() {
+		return fmt.Errorf("manifest mismatch. %d modified, %d added, %d deleted", len(d.Changed), len(d.Added), len(d.Removed))
+	}
+	return nil
+}
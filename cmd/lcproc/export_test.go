@@ -0,0 +1,70 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오후 2:40
+ */
+
+package main
+
+import (
+	"github.com/fatima-go/fatima-cmd/extract"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteTarGzSkipRuntimeFiles(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "proc"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "log"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sample.yaml"), []byte("port: 9190\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "proc", "sample.pid"), []byte("1234"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "log", "sample.out"), []byte("started"), 0644))
+
+	output := filepath.Join(t.TempDir(), "sample.tar.gz")
+	assert.Nil(t, writeTarGz(dir, output))
+
+	extracted := t.TempDir()
+	assert.Nil(t, extract.TarGz(output, extracted, extract.DefaultOptions()))
+
+	_, err := os.Stat(filepath.Join(extracted, "sample.yaml"))
+	assert.Nil(t, err)
+	info, err := os.Stat(filepath.Join(extracted, "proc"))
+	assert.Nil(t, err)
+	assert.True(t, info.IsDir())
+	_, err = os.Stat(filepath.Join(extracted, "proc", "sample.pid"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(extracted, "log", "sample.out"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteTarGzKeepExistingOutput(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "sample.tar.gz")
+	assert.Nil(t, os.WriteFile(output, []byte("keep"), 0644))
+
+	assert.NotNil(t, writeTarGz(t.TempDir(), output))
+	data, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, "keep", string(data))
+
+	// 생성한 output 은 실패하면 삭제한다
+	output = filepath.Join(t.TempDir(), "missing.tar.gz")
+	assert.NotNil(t, writeTarGz(filepath.Join(t.TempDir(), "missing"), output))
+	_, err = os.Stat(output)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 8:30
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/localproc"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type processInfo struct {
+	Process    string              `json:"process"`
+	AppLink    string              `json:"app_link"`
+	LinkTarget string              `json:"link_target,omitempty"`
+	Revision   string              `json:"revision,omitempty"`
+	Build      *DeploymentBuild    `json:"build,omitempty"`
+	Pid        int                 `json:"pid,omitempty"`
+	Status     string              `json:"status"`
+	Stat       *localproc.ProcStat `json:"stat,omitempty"`
+	Uptime     string              `json:"uptime,omitempty"`
+	Binary     *binaryInfo         `json:"binary,omitempty"`
+}
+
+type binaryInfo struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Modified string `json:"modified"`
+}
+
+func showInfo() {
+	infoCommand := flag.NewFlagSet("info", flag.ExitOnError)
+	var asJson bool
+	infoCommand.BoolVar(&asJson, "json", false, "print as json")
+	_ = infoCommand.Parse(os.Args[3:])
+
+	info := collectInfo(proc)
+	if asJson {
+		b, _ := json.MarshalIndent(info, "", "  ")
+		fmt.Printf("%s\n", b)
+		return
+	}
+
+	printInfo(info)
+}
+
+func collectInfo(proc string) processInfo {
+	info := processInfo{Process: proc, AppLink: localproc.AppDir(proc)}
+
+	if target, err := os.Readlink(info.AppLink); err == nil {
+		info.LinkTarget = target
+	}
+	if number, err := getCurrentRevision(proc); err == nil {
+		info.Revision = fmt.Sprintf("R%03d", number)
+	}
+	if deployment, err := readDeployment(info.AppLink); err == nil && deployment.HasBuildInfo() {
+		info.Build = &deployment.Build
+	}
+
+	process, err := localproc.Lookup(proc)
+	if err != nil {
+		info.Status = err.Error()
+	} else {
+		info.Pid = process.Pid
+		info.Status = process.Status.String()
+	}
+
+	if process.IsRunning() {
+		if stat, err := localproc.Stat(process.Pid); err == nil {
+			info.Stat = &stat
+			info.Uptime = stat.Uptime().String()
+		}
+	}
+
+	for _, name := range []string{proc, proc + ".sh"} {
+		path := filepath.Join(info.AppLink, name)
+		if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() {
+			info.Binary = &binaryInfo{Path: path, Size: fi.Size(), Modified: fi.ModTime().Format(yyyyMMddHHmmss)}
+			break
+		}
+	}
+
+	return info
+}
+
+func printInfo(info processInfo) {
+	fmt.Printf("%-10s: %s\n", "process", info.Process)
+	if len(info.LinkTarget) > 0 {
+		fmt.Printf("%-10s: %s -> %s\n", "app link", info.AppLink, info.LinkTarget)
+	} else {
+		fmt.Printf("%-10s: %s\n", "app link", info.AppLink)
+	}
+	if len(info.Revision) > 0 {
+		fmt.Printf("%-10s: %s\n", "revision", info.Revision)
+	}
+	if info.Build != nil {
+		fmt.Printf("%-10s: %s (%s)\n", "build", info.Build.BuildTime, info.Build.BuildUser)
+		if info.Build.HasGit() {
+			fmt.Printf("%-10s: %s\n", "git", info.Build.Git.String())
+			if info.Build.Git.HasMessage() {
+				fmt.Printf("%-10s: %s\n", "message", GetTrimmedMessage(info.Build.Git.Message))
+			}
+		}
+	}
+
+	if info.Pid > 0 {
+		fmt.Printf("%-10s: %d (%s)\n", "pid", info.Pid, info.Status)
+	} else {
+		fmt.Printf("%-10s: %s\n", "pid", info.Status)
+	}
+
+	if info.Stat != nil {
+		fmt.Printf("%-10s: %s (since %s)\n", "uptime", info.Uptime, info.Stat.StartTime.Format(yyyyMMddHHmmss))
+		fmt.Printf("%-10s: %s\n", "rss", humanSize(info.Stat.RSS))
+		fmt.Printf("%-10s: %d\n", "threads", info.Stat.Threads)
+		fmt.Printf("%-10s: %d\n", "open fds", info.Stat.OpenFds)
+		ports := make([]string, 0)
+		for _, p := range info.Stat.ListenPorts {
+			ports = append(ports, fmt.Sprintf("%d", p))
+		}
+		fmt.Printf("%-10s: %s\n", "listen", strings.Join(ports, ", "))
+	}
+
+	if info.Binary != nil {
+		fmt.Printf("%-10s: %s (%s, %s)\n", "binary", info.Binary.Path, humanSize(info.Binary.Size), info.Binary.Modified)
+	}
+}
+
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
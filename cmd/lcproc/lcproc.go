@@ -21,26 +21,48 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 )
 
-var roPrograms = []string{"jupiter", "juno", "saturn"}
-
-var usage = `usage: %s process command [parameter]
+var usage = `usage: %s process|all command [parameter]
 
 display/control process version, duplicate process
 
 positional arguments:
   process		process name
-  command		version/dup
+  command		version/dup/rollback/diff/pin/unpin/verify/export/import/info/buildinfo/dedupe/overlay
 
 example :
 
-lcproc mypgm version		: display mypgm revision versions
-lcproc mypgm version R017	: change mypgm revision to R017
-lcproc mypgm dup mypgm2		: duplicate mypgm to mypgm2
+lcproc mypgm version			: display mypgm revision versions
+lcproc mypgm version R017		: change mypgm revision to R017
+lcproc mypgm dup mypgm2			: duplicate mypgm to mypgm2
+lcproc mypgm dup mypgm2 --set port=9191 --register --group 4
+					: duplicate with config override and register mypgm2 to package
+lcproc mypgm rollback			: stop mypgm, change to previous revision and start again
+lcproc mypgm rollback --to R016 --yes	: rollback mypgm to R016 without confirmation
+lcproc mypgm rollback --ports mypgm:9200	: check readiness of mypgm by port 9200 (default no port check)
+lcproc mypgm diff R016 R017		: display changed files between R016 and R017
+lcproc mypgm diff R016 R017 --stat	: display only count of changed files
+lcproc mypgm pin R016			: pin mypgm to R016. revision can't be changed until unpin
+lcproc mypgm unpin			: unpin mypgm
+lcproc mypgm verify			: compare current revision files with its sha256 manifest
+lcproc mypgm verify R016		: compare R016 files with its sha256 manifest
+lcproc all version [--json]		: display revision summary of every process including opm
+lcproc all verify			: verify current revision of every process. exit 1 if changed
+lcproc mypgm export R016 -o a.tar.gz	: package R016 revision to a.tar.gz
+lcproc mypgm import a.tar.gz		: install a.tar.gz as next revision without changing revision
+lcproc mypgm info [--json]		: display revision, build info, pid and resource usage of mypgm
+lcproc mypgm buildinfo [R016]		: display go version, modules and vcs info of mypgm binary
+lcproc all buildinfo [--format json]	: display go build info of every process as csv(default) or json
+lcproc mypgm dedupe [--dry-run]		: hardlink identical files across revisions of mypgm (except config, proc, log)
+lcproc all dedupe [--dry-run]		: hardlink identical files across revisions of every process
+lcproc mypgm overlay			: display overlay files applied on every revision switch
+lcproc mypgm overlay capture [file...]	: save locally changed files of current revision to overlay
+lcproc mypgm overlay apply [R017]	: apply overlay files to revision
 `
 
 var proc string
@@ -55,6 +77,11 @@ func main() {
 	}
 
 	proc = strings.ToLower(os.Args[1])
+	if proc == "all" {
+		allCommand(strings.ToLower(os.Args[2]))
+		return
+	}
+
 	if isRoProgram(proc) {
 		fmt.Printf("not permitted ro programs (e.g juno,jupiter,saturn)\n")
 		return
@@ -63,6 +90,28 @@ func main() {
 	cmd = strings.ToLower(strings.ToLower(os.Args[2]))
 	if cmd == "version" {
 		versioning()
+	} else if cmd == "rollback" {
+		rollback()
+	} else if cmd == "diff" {
+		diffRevision()
+	} else if cmd == "pin" {
+		pinRevision()
+	} else if cmd == "unpin" {
+		unpinRevision()
+	} else if cmd == "verify" {
+		verifyRevision()
+	} else if cmd == "export" {
+		exportRevision()
+	} else if cmd == "import" {
+		importRevision()
+	} else if cmd == "info" {
+		showInfo()
+	} else if cmd == "buildinfo" {
+		showBuildInfo()
+	} else if cmd == "dedupe" {
+		dedupeRevision()
+	} else if cmd == "overlay" {
+		overlay()
 	} else if cmd == "dup" {
 		if len(os.Args) < 4 {
 			fmt.Printf(string(usage), os.Args[0])
@@ -73,3 +122,33 @@ func main() {
 		fmt.Printf(string(usage), os.Args[0])
 	}
 }
+
+// allCommand 모든 프로세스를 대상으로 command 를 수행한다
+func allCommand(command string) {
+	switch command {
+	case "verify":
+		verifyAll()
+	case "version":
+		summaryAll()
+	case "buildinfo":
+		buildInfoAll()
+	case "dedupe":
+		dedupeAll()
+	default:
+		fmt.Printf(string(usage), os.Args[0])
+	}
+}
+
+// parseArgs flag 와 positional 인자가 섞여 있어도 flag 를 모두 파싱하고 positional 인자 목록을 리턴한다
+func parseArgs(flagSet *flag.FlagSet, args []string) []string {
+	positional := make([]string, 0)
+	for {
+		_ = flagSet.Parse(args)
+		args = flagSet.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
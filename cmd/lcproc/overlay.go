@@ -0,0 +1,140 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 10:30
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/revision"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"strings"
+)
+
+func overlay() {
+	sub := "list"
+	if len(os.Args) > 3 {
+		sub = strings.ToLower(os.Args[3])
+	}
+
+	switch sub {
+	case "list":
+		listOverlay()
+	case "capture":
+		captureOverlay()
+	case "apply":
+		applyOverlay()
+	default:
+		fmt.Printf(usage, os.Args[0])
+	}
+}
+
+func listOverlay() {
+	o, err := revision.LoadOverlay(proc)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		return
+	}
+
+	if len(o.Files) == 0 {
+		fmt.Printf("%s has no overlay files\n", proc)
+		return
+	}
+
+	fmt.Printf("%s overlay : %s\n", proc, revision.OverlayDir(proc))
+	data := make([][]string, 0)
+	for _, rel := range sortedEntryKeys(o.Files) {
+		data = append(data, []string{rel, o.Files[rel].Revision})
+	}
+	share.PrintTable([]string{"file", "captured from"}, data)
+}
+
+// captureOverlay 현재 revision 에서 직접 수정된 파일을 overlay 디렉토리에 저장한다
+func captureOverlay() {
+	revisions := getRevisions(getRevisionPath(proc))
+	current, ok := findCurrentRevision(proc, revisions)
+	if !ok {
+		fmt.Printf("Not found current revision of %s\n", proc)
+		return
+	}
+
+	captured, err := revision.CaptureOverlay(proc, current.dir, current.revision, os.Args[4:])
+	if err != nil {
+		fmt.Printf("fail to capture overlay : %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if len(captured) == 0 {
+		fmt.Printf("there is no changed file in %s\n", current.revision)
+		return
+	}
+	for _, f := range captured {
+		fmt.Printf("captured : %s\n", f)
+	}
+	fmt.Printf("%d files saved to %s\n", len(captured), revision.OverlayDir(proc))
+}
+
+func applyOverlay() {
+	revisions := getRevisions(getRevisionPath(proc))
+	var target Revision
+	var ok bool
+	if len(os.Args) > 4 {
+		target, ok = getVersion(revisions, strings.ToUpper(os.Args[4]))
+	} else {
+		target, ok = findCurrentRevision(proc, revisions)
+	}
+	if !ok {
+		fmt.Printf("Not found revision\n")
+		return
+	}
+
+	err := applyOverlayTo(proc, target)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// applyOverlayTo revision 에 overlay 를 적용하고 결과를 출력한다
+func applyOverlayTo(proc string, target Revision) error {
+	report, err := revision.ApplyOverlay(proc, target.dir)
+	printOverlayReport(target, report)
+	if err != nil {
+		return fmt.Errorf("fail to apply overlay to %s : %s", target.revision, err.Error())
+	}
+	return nil
+}
+
+func printOverlayReport(target Revision, report revision.OverlayReport) {
+	for _, f := range report.Applied {
+		fmt.Printf("overlay applied to %s : %s\n", target.revision, f)
+	}
+	for _, f := range report.Conflicts {
+		fmt.Printf("CONFLICT : %s is also changed in upstream. check %s\n", f, target.revision)
+	}
+}
+
+func sortedEntryKeys(m map[string]revision.OverlayEntry) []string {
+	keys := make(map[string]string)
+	for k := range m {
+		keys[k] = ""
+	}
+	return revision.SortedKeys(keys)
+}
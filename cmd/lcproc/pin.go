@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 6:30
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/revision"
+	"os"
+	"strings"
+)
+
+func pinRevision() {
+	if len(os.Args) < 4 {
+		fmt.Printf(usage, os.Args[0])
+		return
+	}
+
+	revFolder := getRevisionPath(proc)
+	if !isExistRevision(revFolder) {
+		fmt.Printf("%s revision folder doesn't exist\n", proc)
+		return
+	}
+
+	target, ok := getVersion(getRevisions(revFolder), strings.ToUpper(os.Args[3]))
+	if !ok {
+		fmt.Printf("Not found revision %s\n", os.Args[3])
+		return
+	}
+
+	pin, err := revision.WritePin(proc, target.revision)
+	if err != nil {
+		fmt.Printf("fail to pin %s : %s\n", proc, err.Error())
+		return
+	}
+	fmt.Printf("%s %s\n", proc, pin.String())
+
+	curRev, err := getCurrentRevision(proc)
+	if err == nil && curRev != target.number {
+		fmt.Printf("current revision is R%03d. you can change revision using 'lcproc %s version %s'\n", curRev, proc, target.revision)
+	}
+}
+
+func unpinRevision() {
+	pin, pinned, _ := revision.ReadPin(proc)
+	if !pinned {
+		fmt.Printf("%s is not pinned\n", proc)
+		return
+	}
+
+	err := revision.RemovePin(proc)
+	if err != nil {
+		fmt.Printf("fail to unpin %s : %s\n", proc, err.Error())
+		return
+	}
+	fmt.Printf("%s unpinned from %s\n", proc, pin.Revision)
+}
+
+// checkPinned pin 이 설정된 경우 pin 된 revision 이외의 revision 으로 변경할 수 없다
+func checkPinned(proc string, target Revision) error {
+	pin, pinned, err := revision.ReadPin(proc)
+	if !pinned {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if pin.Revision == target.revision {
+		return nil
+	}
+	return fmt.Errorf("%s is %s. unpin first to change revision", proc, pin.String())
+}
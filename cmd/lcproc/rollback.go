@@ -0,0 +1,217 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 4:25
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/localproc"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	rollbackPrevious      = "previous"
+	processStartTimeout   = 10 * time.Second
+	processStableDuration = 5 * time.Second
+)
+
+type rollbackFlags struct {
+	To      string
+	Yes     bool
+	Timeout time.Duration
+	Ports   string
+}
+
+func buildRollbackFlags() rollbackFlags {
+	cmdFlags := rollbackFlags{}
+
+	rollbackCommand := flag.NewFlagSet("rollback", flag.ExitOnError)
+	rollbackCommand.StringVar(&cmdFlags.To, "to", rollbackPrevious, "target revision. Rxxx or previous")
+	rollbackCommand.BoolVar(&cmdFlags.Yes, "yes", false, "yes all")
+	rollbackCommand.DurationVar(&cmdFlags.Timeout, "timeout", 10*time.Second, "timeout for graceful shutdown before SIGKILL")
+	rollbackCommand.StringVar(&cmdFlags.Ports, "ports", "", localproc.PortsUsage)
+	_ = rollbackCommand.Parse(os.Args[3:])
+
+	return cmdFlags
+}
+
+// rollback 프로세스를 중지하고 revision 을 변경한 후 다시 기동한다.
+// 변경된 revision 으로 기동에 실패할 경우 원래 revision 으로 되돌린다
+func rollback() {
+	flags := buildRollbackFlags()
+
+	ports, err := localproc.ParsePorts(flags.Ports)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		return
+	}
+	port := ports[proc]
+
+	revFolder := getRevisionPath(proc)
+	if !isExistRevision(revFolder) {
+		fmt.Printf("%s revision folder doesn't exist\n", proc)
+		return
+	}
+
+	curRev, err := getCurrentRevision(proc)
+	if err != nil {
+		fmt.Printf("error : %s\n", err.Error())
+		return
+	}
+
+	revisions := getRevisions(revFolder)
+	originRevision, ok := getRevisionByNumber(revisions, curRev)
+	if !ok {
+		fmt.Printf("Not found current revision R%03d\n", curRev)
+		return
+	}
+
+	targetRevision, ok := findRollbackRevision(revisions, curRev, flags.To)
+	if !ok {
+		fmt.Printf("Not found revision %s\n", flags.To)
+		return
+	}
+
+	if targetRevision.number == curRev {
+		fmt.Printf("process %s is already %s revision\n", proc, targetRevision.revision)
+		return
+	}
+
+	err = checkPinned(proc, targetRevision)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		return
+	}
+
+	if !flags.Yes && !share.AskYes(fmt.Sprintf("%s :: rollback %s to revision %s?", proc, originRevision.revision, targetRevision.revision)) {
+		return
+	}
+
+	err = stopProcess(proc, flags.Timeout)
+	if err != nil {
+		fmt.Printf("fail to stop process %s : %s\n", proc, err.Error())
+		return
+	}
+
+	link, err := linkRevision(proc, targetRevision)
+	if err != nil {
+		fmt.Printf("fail to link revision %s : %s\n", targetRevision.revision, err.Error())
+		restart(proc, originRevision, port)
+		os.Exit(1)
+	}
+
+	err = startProcess(proc, port)
+	if err == nil {
+		fmt.Printf("process %s rollbacked to %s revision\n", proc, targetRevision.revision)
+		return
+	}
+
+	fmt.Printf("fail to start %s revision : %s\n", targetRevision.revision, err.Error())
+	fmt.Printf("restore to %s revision\n", originRevision.revision)
+
+	_ = stopProcess(proc, flags.Timeout)
+	err = link.Revert()
+	if err != nil {
+		fmt.Printf("fail to restore %s revision link : %s\n", originRevision.revision, err.Error())
+		os.Exit(1)
+	}
+	restart(proc, originRevision, port)
+	os.Exit(1)
+}
+
+// restart 원래 revision 의 프로세스를 다시 기동한다
+func restart(proc string, origin Revision, port int) {
+	err := startProcess(proc, port)
+	if err != nil {
+		fmt.Printf("fail to restore %s revision : %s\n", origin.revision, err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("process %s restored to %s revision\n", proc, origin.revision)
+}
+
+// findRollbackRevision to 가 previous 일 경우 현재 revision 바로 이전 revision 을 찾는다
+func findRollbackRevision(revisions []Revision, curRev int, to string) (Revision, bool) {
+	if strings.ToLower(to) != rollbackPrevious {
+		return getVersion(revisions, strings.ToUpper(to))
+	}
+
+	// revisions 는 number 의 역순으로 정렬되어 있다
+	for _, r := range revisions {
+		if r.number < curRev {
+			return r, true
+		}
+	}
+
+	return Revision{}, false
+}
+
+func getRevisionByNumber(revisions []Revision, number int) (Revision, bool) {
+	for _, r := range revisions {
+		if r.number == number {
+			return r, true
+		}
+	}
+
+	return Revision{}, false
+}
+
+// stopProcess localproc.Stop 으로 프로세스를 종료한다. timeout 이 지나면 SIGKILL 을 보낸다
+func stopProcess(proc string, timeout time.Duration) error {
+	fmt.Printf("stopping process %s\n", proc)
+	result, err := localproc.Stop(proc, timeout)
+	if err != nil {
+		return err
+	}
+
+	if result.Outcome == localproc.StopTerminated || result.Outcome == localproc.StopKilled {
+		fmt.Printf("process %s stopped. pid=%d, %s (%s)\n", proc, result.Pid, result.Outcome, result.Elapsed)
+	}
+	return nil
+}
+
+// startProcess localproc.Start 로 프로세스를 기동하고 readiness 를 확인한다
+func startProcess(proc string, port int) error {
+	fmt.Printf("starting process %s. waiting until it is stable...\n", proc)
+	result, err := localproc.Start(proc, localproc.StartOptions{
+		Timeout:        processStartTimeout,
+		StableDuration: processStableDuration,
+		Port:           port,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch result.Outcome {
+	case localproc.StartNotInstalled:
+		return fmt.Errorf("not found program %s", filepath.Join(localproc.AppDir(proc), proc))
+	case localproc.StartAlreadyRunning:
+		// 종료 후 다른 곳에서 기동한 프로세스는 변경된 revision 으로 기동했는지 알 수 없다
+		return fmt.Errorf("process is already running. pid=%d", result.Pid)
+	}
+
+	fmt.Printf("process %s is running. pid=%d\n", proc, result.Pid)
+	return nil
+}
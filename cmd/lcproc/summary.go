@@ -0,0 +1,141 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 9:00
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/share"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+type revisionSummary struct {
+	Process   string `json:"process"`
+	Current   string `json:"current"`
+	Latest    string `json:"latest"`
+	Count     int    `json:"count"`
+	DiskUsage int64  `json:"disk_usage"` // bytes
+	User      string `json:"user"`
+	Branch    string `json:"branch"`
+	Commit    string `json:"commit"`
+	Outdated  bool   `json:"outdated"` // 최신 revision 을 사용하지 않는 경우
+}
+
+// summaryAll 모든 프로세스의 revision 현황을 출력한다
+func summaryAll() {
+	summaryCommand := flag.NewFlagSet("version", flag.ExitOnError)
+	var asJson bool
+	summaryCommand.BoolVar(&asJson, "json", false, "print as json")
+	_ = summaryCommand.Parse(os.Args[3:])
+
+	// 조회만 하므로 opm 프로그램도 포함한다
+	procs, err := listRevisionProcs(true)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	list := make([]revisionSummary, 0)
+	for _, p := range procs {
+		list = append(list, summarize(p))
+	}
+
+	if asJson {
+		b, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Printf("%s\n", b)
+		return
+	}
+
+	data := make([][]string, 0)
+	for _, s := range list {
+		outdated := ""
+		if s.Outdated {
+			outdated = "*"
+		}
+		data = append(data, []string{s.Process, s.Current, s.Latest, strconv.Itoa(s.Count),
+			humanSize(s.DiskUsage), s.User, s.Branch, s.Commit, outdated})
+	}
+	share.PrintTable([]string{"process", "current", "latest", "count", "disk", "user", "branch", "commit", "outdated"}, data)
+}
+
+func summarize(proc string) revisionSummary {
+	s := revisionSummary{Process: proc}
+	revFolder := getRevisionPath(proc)
+	revisions := getRevisions(revFolder)
+	s.Count = len(revisions)
+	s.DiskUsage = diskUsage(revFolder)
+	if len(revisions) == 0 {
+		return s
+	}
+
+	// revisions 는 number 의 역순으로 정렬되어 있다. 배포 정보는 마지막으로 배포된 revision 의 정보이다
+	latest := revisions[0]
+	s.Latest = latest.revision
+	s.User = latest.deployment.BuildUser
+	s.Branch = latest.deployment.Git.Branch
+	s.Commit = latest.deployment.Git.Commit
+	if len(s.Commit) > 8 {
+		s.Commit = s.Commit[:8]
+	}
+
+	current, ok := findCurrentRevision(proc, revisions)
+	if !ok {
+		return s
+	}
+	s.Current = current.revision
+	s.Outdated = current.number != latest.number
+	return s
+}
+
+// diskUsage dir 하위 파일 크기의 합. dedupe 로 hardlink 된 파일은 한번만 더한다
+func diskUsage(dir string) int64 {
+	type inode struct {
+		dev uint64
+		ino uint64
+	}
+
+	var total int64
+	seen := make(map[inode]bool)
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Nlink > 1 {
+			key := inode{dev: uint64(st.Dev), ino: uint64(st.Ino)}
+			if seen[key] {
+				return nil
+			}
+			seen[key] = true
+		}
+		total += info.Size()
+		return nil
+	})
+	return total
+}
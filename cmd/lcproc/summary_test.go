@@ -0,0 +1,62 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오후 5:00
+ */
+
+package main
+
+import (
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+	revFolder := getRevisionPath("juno")
+	r1 := filepath.Join(revFolder, "2023.01.01-10.00_R001")
+	r2 := filepath.Join(revFolder, "2023.01.02-10.00_R002")
+	for i, dir := range []string{r1, r2} {
+		assert.Nil(t, os.MkdirAll(dir, 0755))
+		deployment := []byte(`{"process":"juno","build":{"user":"user` + string(rune('1'+i)) + `","git":{"branch":"main","commit":"0123456789abcdef"}}}`)
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, deploymentJsonFile), deployment, 0644))
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(r1, "juno"), make([]byte, 1000), 0755))
+	// dedupe 로 hardlink 된 파일은 한번만 계산한다
+	assert.Nil(t, os.Link(filepath.Join(r1, "juno"), filepath.Join(r2, "juno")))
+	assert.Nil(t, os.Symlink(r1, filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderApp, "juno")))
+
+	procs, err := listRevisionProcs(true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"juno"}, procs)
+	procs, err = listRevisionProcs(false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(procs))
+
+	s := summarize("juno")
+	assert.Equal(t, "R001", s.Current)
+	assert.Equal(t, "R002", s.Latest)
+	assert.True(t, s.Outdated)
+	assert.Equal(t, "user2", s.User)
+	assert.Equal(t, "01234567", s.Commit)
+
+	info, _ := os.Stat(filepath.Join(r1, deploymentJsonFile))
+	assert.Equal(t, 1000+2*info.Size(), s.DiskUsage)
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 7:00
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/revision"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	verifyResultOk         = "OK"
+	verifyResultDrift      = "DRIFT"
+	verifyResultNoBaseline = "NO BASELINE"
+	verifyResultError      = "ERROR"
+)
+
+type verifyResult struct {
+	proc     string
+	revision string
+	result   string
+	message  string
+	diff     revision.Diff
+}
+
+func (v verifyResult) IsFailed() bool {
+	return v.result == verifyResultDrift || v.result == verifyResultError
+}
+
+// verifyRevision revision 디렉토리의 파일을 manifest 와 비교한다. 변경된 파일이 있으면 exit code 1 로 종료한다
+func verifyRevision() {
+	revFolder := getRevisionPath(proc)
+	if !isExistRevision(revFolder) {
+		fmt.Printf("%s revision folder doesn't exist\n", proc)
+		os.Exit(1)
+	}
+
+	var target Revision
+	var ok bool
+	revisions := getRevisions(revFolder)
+	if len(os.Args) > 3 {
+		target, ok = getVersion(revisions, strings.ToUpper(os.Args[3]))
+	} else {
+		target, ok = findCurrentRevision(proc, revisions)
+	}
+	if !ok {
+		fmt.Printf("Not found revision to verify\n")
+		os.Exit(1)
+	}
+
+	result := verify(proc, target)
+	printVerifyResult(result)
+	if result.IsFailed() {
+		os.Exit(1)
+	}
+}
+
+// verifyAll 모든 프로세스의 현재 revision 을 검사한다
+func verifyAll() {
+	procs, err := listRevisionProcs(false)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	failed := false
+	results := make([]verifyResult, 0)
+	data := make([][]string, 0)
+	for _, p := range procs {
+		target, ok := findCurrentRevision(p, getRevisions(getRevisionPath(p)))
+		if !ok {
+			continue
+		}
+		result := verify(p, target)
+		results = append(results, result)
+		data = append(data, []string{result.proc, result.revision, result.result, result.message})
+		failed = failed || result.IsFailed()
+	}
+	share.PrintTable([]string{"process", "revision", "result", "message"}, data)
+
+	for _, r := range results {
+		if r.result == verifyResultDrift {
+			fmt.Printf("\n")
+			printVerifyResult(r)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// listRevisionProcs $FATIMA_HOME/app/revision 하위의 프로세스 목록을 이름순으로 리턴한다. includeRo 가 false 이면 opm 프로그램은 제외한다
+func listRevisionProcs(includeRo bool) ([]string, error) {
+	revisionDir := filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderApp, share.FatimaFolderRevision)
+	entries, err := os.ReadDir(revisionDir)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read %s : %s", revisionDir, err.Error())
+	}
+
+	procs := make([]string, 0)
+	for _, e := range entries {
+		if e.IsDir() && (includeRo || !isRoProgram(e.Name())) {
+			procs = append(procs, e.Name())
+		}
+	}
+	sort.Strings(procs)
+	return procs, nil
+}
+
+func findCurrentRevision(proc string, revisions []Revision) (Revision, bool) {
+	curRev, err := getCurrentRevision(proc)
+	if err != nil {
+		return Revision{}, false
+	}
+	return getRevisionByNumber(revisions, curRev)
+}
+
+func verify(proc string, target Revision) verifyResult {
+	result := verifyResult{proc: proc, revision: target.revision}
+
+	d, err := revision.Verify(target.dir)
+	switch {
+	case errors.Is(err, revision.ErrNoManifest):
+		result.result = verifyResultNoBaseline
+		result.message = err.Error()
+	case err != nil:
+		result.result = verifyResultError
+		result.message = err.Error()
+	case d.IsEmpty():
+		result.result = verifyResultOk
+	default:
+		result.result = verifyResultDrift
+		result.message = fmt.Sprintf("%d modified, %d added, %d deleted", len(d.Changed), len(d.Added), len(d.Removed))
+		result.diff = d
+	}
+	return result
+}
+
+func printVerifyResult(r verifyResult) {
+	fmt.Printf("%s %s : %s", r.proc, r.revision, r.result)
+	if len(r.message) > 0 {
+		fmt.Printf(" (%s)", r.message)
+	}
+	fmt.Printf("\n")
+
+	printFileList(os.Stdout, "modified", "M", r.diff.Changed)
+	printFileList(os.Stdout, "added", "A", r.diff.Added)
+	printFileList(os.Stdout, "deleted", "D", r.diff.Removed)
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오후 4:10
+ */
+
+package main
+
+import (
+	"github.com/fatima-go/fatima-cmd/revision"
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyNoBaseline(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sample.yaml"), []byte("port: 9190\n"), 0644))
+	target := Revision{revision: "R001", dir: dir}
+
+	// verify 는 manifest 를 생성하지 않는다
+	result := verify("sample", target)
+	assert.Equal(t, verifyResultNoBaseline, result.result)
+	assert.False(t, result.IsFailed())
+	assert.False(t, share.IsFileExist(filepath.Join(dir, revision.ManifestFileName)))
+
+	_, err := revision.WriteManifest(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, verifyResultOk, verify("sample", target).result)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sample.yaml"), []byte("port: 9191\n"), 0644))
+	result = verify("sample", target)
+	assert.Equal(t, verifyResultDrift, result.result)
+	assert.Equal(t, []string{"sample.yaml"}, result.diff.Changed)
+	assert.True(t, result.IsFailed())
+}
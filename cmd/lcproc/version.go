@@ -21,16 +21,15 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"github.com/fatima-go/fatima-cmd/config"
+	"github.com/fatima-go/fatima-cmd/localproc"
+	"github.com/fatima-go/fatima-cmd/revision"
 	"github.com/fatima-go/fatima-cmd/share"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -57,15 +56,22 @@ func versioning() {
 	revisions := getRevisions(revFolder)
 
 	if len(os.Args) == 3 {
+		pin, pinned, _ := revision.ReadPin(proc)
 		fmt.Printf("%s revisions...\n", proc)
 		for _, r := range revisions {
+			current, pinMark := "   ", "   "
 			if r.number == curRev {
-				fmt.Printf("%s [O] ", r.revision)
-			} else {
-				fmt.Printf("%s     ", r.revision)
+				current = "[O]"
 			}
+			if pinned && r.revision == pin.Revision {
+				pinMark = "[P]"
+			}
+			fmt.Printf("%s %s %s ", r.revision, current, pinMark)
 			fmt.Printf("%s\n", r.GetBuildSummary())
 		}
+		if pinned {
+			fmt.Printf("%s %s\n", proc, pin.String())
+		}
 		return
 	}
 
@@ -81,31 +87,31 @@ func versioning() {
 		return
 	}
 
-	reader := bufio.NewReader(os.Stdin)
-	for true {
-		fmt.Printf("%s :: reset to revision %s? (y/n) ", proc, newVersion)
-		text, _ := reader.ReadString('\n')
-		if len(text) == 0 {
-			continue
-		}
-		answer := strings.ToLower(strings.Trim(text, "\r\n\t "))
-		if answer == "n" {
-			return
-		} else if answer == "y" {
-			break
-		}
+	err = checkPinned(proc, newRevision)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		return
 	}
 
-	pid := readPidFromFile(proc)
-	if pid > 0 {
-		if isPidExist(pid) {
-			fmt.Printf("pid %d exist. firstly, you have to stop process\n", pid)
-			return
-		}
+	if !share.AskYes(fmt.Sprintf("%s :: reset to revision %s?", proc, newVersion)) {
+		return
+	}
+
+	process, err := localproc.Lookup(proc)
+	if err != nil {
+		fmt.Printf("fail to check process : %s\n", err.Error())
+		return
+	}
+	if process.IsRunning() {
+		fmt.Printf("pid %d exist. firstly, you have to stop process\n", process.Pid)
+		return
+	}
+	if process.Status == localproc.StatusStale {
+		fmt.Printf("ignore stale pid file : %s (pid %d)\n", process.PidFile, process.Pid)
 	}
 
 	// link again to new version
-	err = linkRevision(proc, newRevision)
+	_, err = linkRevision(proc, newRevision)
 	if err != nil {
 		fmt.Printf("fail to link revision to %s : %s\n", newVersion, err.Error())
 		return
@@ -116,8 +122,8 @@ func versioning() {
 
 func isRoProgram(proc string) bool {
 	comp := strings.ToLower(proc)
-	for _, p := range roPrograms {
-		if p == comp {
+	for _, p := range config.OpmProgramNames(os.Getenv(share.EnvFatimaHome)) {
+		if strings.ToLower(p) == comp {
 			return true
 		}
 	}
@@ -207,13 +213,13 @@ func getRevisions(revFolder string) []Revision {
 func readRevision(revision Revision) Revision {
 	f, err := os.Open(revision.dir)
 	if err != nil {
-		fmt.Printf("fail to open %s : %s", revision.dir, err.Error())
+		fmt.Fprintf(os.Stderr, "fail to open %s : %s", revision.dir, err.Error())
 		return revision
 	}
 
 	info, _ := f.Stat()
 	if !info.IsDir() {
-		fmt.Printf("revision path is not directory %s", revision.dir)
+		fmt.Fprintf(os.Stderr, "revision path is not directory %s", revision.dir)
 		return revision
 	}
 
@@ -223,14 +229,14 @@ func readRevision(revision Revision) Revision {
 	deploymentFile := filepath.Join(revision.dir, deploymentJsonFile)
 	file, err := os.ReadFile(deploymentFile)
 	if err != nil {
-		fmt.Printf("readfile %s err : %s\n", deploymentFile, err.Error())
+		fmt.Fprintf(os.Stderr, "readfile %s err : %s\n", deploymentFile, err.Error())
 		return revision
 	}
 
 	deployment := Deployment{}
 	err = json.Unmarshal(file, &deployment)
 	if err != nil {
-		fmt.Printf("json unmarshal err : %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "json unmarshal err : %s\n", err.Error())
 		return revision
 	}
 
@@ -281,97 +287,14 @@ func getVersion(revisions []Revision, newVer string) (Revision, bool) {
 	return Revision{}, false
 }
 
-func readPidFromFile(procName string) int {
-	pidFile := filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderApp, procName, share.FatimaFolderAppProc, procName+".pid")
-
-	data, err := os.ReadFile(pidFile)
-	if err != nil {
-		return 0
-	}
-	var pid = 0
-	pid, err = strconv.Atoi(strings.Trim(string(data), "\r\n"))
-	if err != nil {
-		fmt.Errorf("fail to parse proc[%s] pid value to int : %s\n", procName, err.Error())
-		return 0
-	}
-
-	return pid
-}
-
-func linkRevision(proc string, revision Revision) error {
-	// unlink $FATIMA_HOME/app/example
-	appDir := filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderApp)
-	appLink := filepath.Join(appDir, proc)
-	fmt.Printf("remove applink : %s\n", appLink)
-	err := os.Remove(appLink)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("fail to remove applink : %s", err.Error())
-		}
-	}
-
-	// link revdir to $FATIMA_HOME/app/example
-	relPath, e := filepath.Rel(appDir, revision.dir)
-	if e != nil {
-		return fmt.Errorf("fail to create relative link : %s", e.Error())
-	}
-
-	command := fmt.Sprintf("ln -s %s %s", relPath, proc)
-	fmt.Printf("create applink : %s\n", command)
-
-	var cmd *exec.Cmd
-	s := regexp.MustCompile("\\s+").Split(command, -1)
-	cmd = exec.Command(s[0], s[1:]...)
-	cmd.Dir = appDir
-	e = cmd.Run()
-	if e != nil {
-		return fmt.Errorf("fail to process link. command=[%s], err=[%s]", command, e.Error())
-	}
-
-	return nil
-}
-
-func executeShell(command string) (string, error) {
-	if len(command) == 0 {
-		return "", errors.New("empty command")
-	}
-
-	var cmd *exec.Cmd
-	cmd = exec.Command("/bin/sh", "-c", command)
-
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err := cmd.Run()
-	if err != nil {
-		return "", err
-	}
-	return out.String(), nil
-}
-
-func isPidExist(pid int) bool {
-	command := fmt.Sprintf("ps")
-	out, err := executeShell(command)
+// linkRevision overlay 를 적용한 후 $FATIMA_HOME/app/<proc> link 를 revision 디렉토리로 변경한다
+func linkRevision(proc string, rev Revision) (revision.LinkSwitch, error) {
+	s, report, err := revision.Switch(proc, rev.dir)
+	printOverlayReport(rev, report)
 	if err != nil {
-		fmt.Printf("fail to execute command : %s\n", err.Error())
-		return true
+		return s, fmt.Errorf("fail to switch to %s : %s", rev.revision, err.Error())
 	}
 
-	scanner := bufio.NewScanner(strings.NewReader(out))
-	for scanner.Scan() {
-		line := scanner.Text()
-		line = strings.TrimLeft(line, "\r\t\n ")
-		items := strings.Split(line, " ")
-		if len(items) < 1 {
-			continue
-		}
-		procId, err := strconv.Atoi(items[0])
-		if err != nil {
-			continue
-		}
-		if procId == pid {
-			return true
-		}
-	}
-
-	return false
+	fmt.Printf("switch applink : %s -> %s\n", s.Link, s.Target)
+	return s, nil
 }
@@ -21,11 +21,9 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/fatima-go/fatima-cmd/share"
 	"os"
-	"path/filepath"
 	"strings"
 )
 
@@ -89,48 +87,8 @@ func main() {
 	}
 }
 
-const (
-	saturnWebhookFile = "/data/saturn/webhook.slack"
-)
-
-func getSlackWebhookConfig() string {
-	return filepath.Join(os.Getenv(share.EnvFatimaHome), saturnWebhookFile)
-}
-
-func loadConfig() (slackConfig, error) {
-	var config map[string]*SlackConfig
-
-	dataBytes, err := os.ReadFile(getSlackWebhookConfig())
-	if err != nil {
-		return config, err
-	}
-
-	err = json.Unmarshal(dataBytes, &config)
-	if err != nil {
-		return config, err
-	}
-
-	return config, nil
-}
-
-type SlackConfig struct {
-	Active  bool   `json:"active"`
-	Url     string `json:"url"`
-	Channel string `json:"channel,omitempty"`
-}
-
-func (sc SlackConfig) String() string {
-	if len(sc.Channel) > 0 {
-		return fmt.Sprintf("activate: [%t], hookUri: [%s], channel: [%s]", sc.Active, sc.Url, sc.Channel)
-	}
-
-	return fmt.Sprintf("activate: [%t], hookUri: [%s]", sc.Active, sc.Url)
-}
-
-type slackConfig map[string]*SlackConfig
-
 func printStatus() {
-	config, err := loadConfig()
+	config, err := share.LoadSlackWebhookConfig()
 	if err != nil {
 		fmt.Printf("fail to load slack webhook file : %s\n", err.Error())
 		return
@@ -150,7 +108,7 @@ func printStatus() {
 }
 
 func setAllStatus(turnOn bool) bool {
-	config, err := loadConfig()
+	config, err := share.LoadSlackWebhookConfig()
 	if err != nil {
 		fmt.Printf("fail to load slack webhook file : %s\n", err.Error())
 		return false
@@ -160,7 +118,7 @@ func setAllStatus(turnOn bool) bool {
 		v.Active = turnOn
 	}
 
-	err = saveConfig(config)
+	err = share.SaveSlackWebhookConfig(config)
 	if err != nil {
 		fmt.Printf("fail to save config : %s\n", err.Error())
 		return false
@@ -171,7 +129,7 @@ func setAllStatus(turnOn bool) bool {
 }
 
 func setStatus(partName string, turnOn bool) bool {
-	config, err := loadConfig()
+	config, err := share.LoadSlackWebhookConfig()
 	if err != nil {
 		fmt.Printf("fail to load slack webhook file : %s\n", err.Error())
 		return false
@@ -184,7 +142,7 @@ func setStatus(partName string, turnOn bool) bool {
 	}
 
 	part.Active = turnOn
-	err = saveConfig(config)
+	err = share.SaveSlackWebhookConfig(config)
 	if err != nil {
 		fmt.Printf("fail to save config : %s\n", err.Error())
 		return false
@@ -193,18 +151,3 @@ func setStatus(partName string, turnOn bool) bool {
 	fmt.Printf("set %s to %v\n", partName, turnOn)
 	return true
 }
-
-func saveConfig(config slackConfig) error {
-	bytes, err := json.MarshalIndent(config, "", "\t")
-	if err != nil {
-		return err
-	}
-
-	err = os.WriteFile(getSlackWebhookConfig(), bytes, 0755)
-	if err != nil {
-		fmt.Printf("fail to save config : %s\n", err.Error())
-		return err
-	}
-
-	return nil
-}
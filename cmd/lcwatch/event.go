@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 1:40
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/share"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	eventStarted       = "WATCH STARTED"
+	eventFinished      = "WATCH FINISHED"
+	eventError         = "ERROR"
+	eventStopped       = "STOPPED"
+	eventDead          = "DEAD"
+	eventRestarted     = "RESTARTED"
+	eventRestartFailed = "RESTART FAILED"
+	eventCrashLoop     = "CRASH LOOP"
+	eventRecovered     = "RECOVERED"
+)
+
+const eventTimeFormat = "2006-01-02 15:04:05"
+
+type event struct {
+	Time    time.Time
+	Process string
+	Kind    string
+	Message string
+}
+
+func (e event) String() string {
+	return fmt.Sprintf("%s [%s] %s : %s", e.Time.Format(eventTimeFormat), e.Kind, e.Process, e.Message)
+}
+
+// isAlert slack 으로 알려야 하는 event 인지 확인한다
+func (e event) isAlert() bool {
+	switch e.Kind {
+	case eventDead, eventRestarted, eventRestartFailed, eventCrashLoop, eventRecovered:
+		return true
+	}
+	return false
+}
+
+type eventWriter interface {
+	write(e event)
+}
+
+// eventLog event 를 로그 파일(과 stdout)에 기록하고 설정된 경우 slack 으로 보낸다
+type eventLog struct {
+	out      io.Writer
+	slack    bool
+	hostname string
+}
+
+func newEventLog(logFile string, slack bool) (*eventLog, io.Closer, error) {
+	err := os.MkdirAll(filepath.Dir(logFile), 0755)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to create log dir : %s", err.Error())
+	}
+
+	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to open event log %s : %s", logFile, err.Error())
+	}
+
+	hostname, _ := os.Hostname()
+	return &eventLog{out: io.MultiWriter(os.Stdout, file), slack: slack, hostname: hostname}, file, nil
+}
+
+func (l *eventLog) write(e event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	fmt.Fprintf(l.out, "%s\n", e)
+
+	if !l.slack || !e.isAlert() {
+		return
+	}
+	err := share.PostSlack(share.SlackPartAlarm, fmt.Sprintf("[lcwatch] %s %s %s : %s", l.hostname, e.Process, e.Kind, e.Message))
+	if err != nil {
+		fmt.Fprintf(l.out, "%s [%s] %s : fail to post slack. %s\n", time.Now().Format(eventTimeFormat), eventError, e.Process, err.Error())
+	}
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 1:40
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/config"
+	"github.com/fatima-go/fatima-cmd/localproc"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var usage = `usage: %s [options] [program...]
+
+watch opm programs and restart crashed ones.
+a program is restarted only when its pid file remains but the process is dead on two consecutive checks.
+programs stopped by stopro (pid file removed) are not restarted.
+
+lcwatch runs in foreground. run it under systemd(Type=simple) or standalone e.g) nohup lcwatch -slack &
+with systemd, set KillMode=process so that programs restarted by lcwatch are not killed when lcwatch stops.
+
+optional arguments:
+  -interval		check interval (default 10s)
+  -backoff		initial delay between restarts. doubled on every restart (default 5s)
+  -max-backoff		max delay between restarts (default 5m)
+  -crash-window		crash loop detection window (default 10m)
+  -max-restarts		max restarts within crash window. restarting stops after it (default 5)
+  -t			timeout for each readiness check while starting (default 30s)
+  -ports		ports to check readiness. e.g) jupiter:9190,juno:9180. empty to skip
+  -log			event log file (default $FATIMA_HOME/log/lcwatch.log)
+  -slack		post events to saturn slack webhook (alarm) in data/saturn/webhook.slack
+  -once			check once and exit
+
+example :
+
+lcwatch				: watch all opm programs whose startmode is always
+lcwatch -slack juno		: watch juno only and post events to slack
+`
+
+type commandFlags struct {
+	Interval       time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	CrashWindow    time.Duration
+	MaxRestarts    int
+	Timeout        time.Duration
+	Ports          string
+	LogFile        string
+	Slack          bool
+	Once           bool
+	Args           []string
+}
+
+func main() {
+	if len(os.Getenv(share.EnvFatimaHome)) == 0 {
+		fmt.Printf("env %s missing\n", share.EnvFatimaHome)
+		os.Exit(1)
+	}
+
+	flags := buildCommandFlags()
+
+	ports, err := localproc.ParsePorts(flags.Ports)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	names, err := watchTargets(flags.Args)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	if len(names) == 0 {
+		fmt.Printf("there is no program to watch\n")
+		os.Exit(1)
+	}
+
+	if len(flags.LogFile) == 0 {
+		flags.LogFile = filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderLog, "lcwatch.log")
+	}
+	events, closer, err := newEventLog(flags.LogFile, flags.Slack)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	defer closer.Close()
+
+	options := watchOptions{
+		InitialBackoff: flags.InitialBackoff,
+		MaxBackoff:     flags.MaxBackoff,
+		CrashWindow:    flags.CrashWindow,
+		MaxRestarts:    flags.MaxRestarts,
+		StartTimeout:   flags.Timeout,
+		Ports:          ports,
+	}
+	w := newWatcher(names, options, events)
+
+	if flags.Once {
+		w.check()
+		return
+	}
+
+	watch(w, flags.Interval)
+}
+
+// watch signal 을 받을 때까지 interval 마다 프로그램을 확인한다
+func watch(w *watcher, interval time.Duration) {
+	names := make([]string, 0)
+	for _, p := range w.programs {
+		names = append(names, p.name)
+	}
+	w.events.write(event{Process: strings.Join(names, ","), Kind: eventStarted,
+		Message: fmt.Sprintf("interval=%s, pid=%d", interval, os.Getpid())})
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		w.check()
+
+		select {
+		case s := <-sig:
+			w.events.write(event{Process: strings.Join(names, ","), Kind: eventFinished, Message: fmt.Sprintf("signal %s", s)})
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchTargets 지정된 프로그램 또는 startmode 가 always 인 opm 프로그램 목록을 리턴한다
+func watchTargets(args []string) ([]string, error) {
+	opmPrograms, err := config.LoadOpmPrograms(os.Getenv(share.EnvFatimaHome))
+	if err != nil {
+		fmt.Printf("%s. use default opm programs\n", err.Error())
+	}
+
+	names := make([]string, 0)
+	if len(args) == 0 {
+		for _, p := range opmPrograms {
+			if p.IsAutoStart() {
+				names = append(names, p.Name)
+			}
+		}
+		return names, nil
+	}
+
+	for _, arg := range args {
+		found := false
+		for _, p := range opmPrograms {
+			if strings.ToLower(arg) == strings.ToLower(p.Name) {
+				names = append(names, p.Name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s is not opm program", arg)
+		}
+	}
+	return names, nil
+}
+
+func buildCommandFlags() commandFlags {
+	cmdFlags := commandFlags{}
+
+	flag.Usage = func() {
+		fmt.Printf(usage, os.Args[0])
+	}
+	flag.DurationVar(&cmdFlags.Interval, "interval", 10*time.Second, "check interval")
+	flag.DurationVar(&cmdFlags.InitialBackoff, "backoff", 5*time.Second, "initial delay between restarts")
+	flag.DurationVar(&cmdFlags.MaxBackoff, "max-backoff", 5*time.Minute, "max delay between restarts")
+	flag.DurationVar(&cmdFlags.CrashWindow, "crash-window", 10*time.Minute, "crash loop detection window")
+	flag.IntVar(&cmdFlags.MaxRestarts, "max-restarts", 5, "max restarts within crash window")
+	flag.DurationVar(&cmdFlags.Timeout, "t", 30*time.Second, "timeout for each readiness check")
+	flag.StringVar(&cmdFlags.Ports, "ports", localproc.DefaultPorts, localproc.PortsUsage)
+	flag.StringVar(&cmdFlags.LogFile, "log", "", "event log file")
+	flag.BoolVar(&cmdFlags.Slack, "slack", false, "post events to slack webhook")
+	flag.BoolVar(&cmdFlags.Once, "once", false, "check once and exit")
+
+	flag.Parse()
+
+	cmdFlags.Args = flag.Args()
+	return cmdFlags
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 1:40
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/localproc"
+	"time"
+)
+
+type watchOptions struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	CrashWindow    time.Duration // 이 시간 동안 MaxRestarts 번 재기동하면 crash loop 로 판단한다
+	MaxRestarts    int
+	StartTimeout   time.Duration
+	Ports          map[string]int // 기동 후 readiness 를 확인할 프로그램 별 port
+}
+
+// programWatch 프로그램 별 재기동 상태
+type programWatch struct {
+	name         string
+	lastStatus   localproc.Status
+	backoff      time.Duration
+	nextRestart  time.Time
+	restarts     []time.Time
+	crashLoop    bool
+	suspectPid   int  // 이전 확인에서 stale 로 발견한 pid
+	notInstalled bool // 실행 파일이 없어 재기동을 멈춘 상태
+}
+
+type watcher struct {
+	programs []*programWatch
+	options  watchOptions
+	events   eventWriter
+	now      func() time.Time
+	lookup   func(proc string) (localproc.Process, error)
+	start    func(proc string, options localproc.StartOptions) (localproc.StartResult, error)
+}
+
+func newWatcher(names []string, options watchOptions, events eventWriter) *watcher {
+	w := &watcher{
+		options: options,
+		events:  events,
+		now:     time.Now,
+		lookup:  localproc.Lookup,
+		start:   localproc.Start,
+	}
+	for _, name := range names {
+		w.programs = append(w.programs, &programWatch{
+			name:       name,
+			lastStatus: localproc.StatusNotRunning,
+			backoff:    options.InitialBackoff,
+		})
+	}
+	return w
+}
+
+// check 모든 프로그램의 상태를 확인하고 죽은 프로그램을 재기동한다
+func (w *watcher) check() {
+	for _, p := range w.programs {
+		w.checkProgram(p)
+	}
+}
+
+// checkProgram pid 파일이 남아있는데 프로세스가 없으면(stale) 비정상 종료로 판단하고 재기동한다.
+// stopro 등으로 정상 종료하면 pid 파일이 삭제되므로 재기동하지 않는다.
+// localproc.Stop 은 프로세스가 종료된 후에 pid 파일을 삭제하므로 그 사이에 확인한 경우를 구분하기 위해
+// 같은 pid 가 다음 확인에서도 stale 일 때 재기동한다
+func (w *watcher) checkProgram(p *programWatch) {
+	now := w.now()
+	process, err := w.lookup(p.name)
+	if err != nil {
+		w.events.write(event{Process: p.name, Kind: eventError, Message: err.Error()})
+		return
+	}
+
+	defer func() {
+		p.lastStatus = process.Status
+	}()
+
+	switch process.Status {
+	case localproc.StatusRunning:
+		if p.lastStatus != localproc.StatusRunning && p.crashLoop {
+			w.events.write(event{Process: p.name, Kind: eventRecovered, Message: fmt.Sprintf("pid %d is running", process.Pid)})
+		}
+		p.crashLoop = false
+		p.notInstalled = false
+		p.suspectPid = 0
+		if len(p.restarts) > 0 && now.Sub(p.restarts[len(p.restarts)-1]) > w.options.CrashWindow {
+			// crash window 동안 안정적으로 실행되었으므로 backoff 를 초기화한다
+			p.restarts = nil
+			p.backoff = w.options.InitialBackoff
+		}
+		return
+	case localproc.StatusNotRunning:
+		if p.lastStatus == localproc.StatusRunning || p.suspectPid > 0 {
+			w.events.write(event{Process: p.name, Kind: eventStopped, Message: "pid file removed. not restarted"})
+		}
+		p.notInstalled = false
+		p.suspectPid = 0
+		return
+	}
+
+	if p.suspectPid != process.Pid {
+		// 종료 중인 프로세스일 수 있으므로 다음 확인때 판단한다
+		p.suspectPid = process.Pid
+		return
+	}
+
+	if p.crashLoop || p.notInstalled || now.Before(p.nextRestart) {
+		return
+	}
+
+	w.events.write(event{Process: p.name, Kind: eventDead, Message: fmt.Sprintf("pid %d is not alive", process.Pid)})
+
+	p.restarts = pruneBefore(p.restarts, now.Add(-w.options.CrashWindow))
+	if len(p.restarts) >= w.options.MaxRestarts {
+		p.crashLoop = true
+		w.events.write(event{Process: p.name, Kind: eventCrashLoop,
+			Message: fmt.Sprintf("restarted %d times within %s. stop restarting until it runs again", len(p.restarts), w.options.CrashWindow)})
+		return
+	}
+
+	p.restarts = append(p.restarts, now)
+	p.nextRestart = now.Add(p.backoff)
+	p.backoff = p.backoff * 2
+	if p.backoff > w.options.MaxBackoff {
+		p.backoff = w.options.MaxBackoff
+	}
+
+	result, err := w.start(p.name, localproc.StartOptions{Timeout: w.options.StartTimeout, Port: w.options.Ports[p.name]})
+	if err != nil {
+		w.events.write(event{Process: p.name, Kind: eventRestartFailed,
+			Message: fmt.Sprintf("%s. next attempt after %s", err.Error(), p.nextRestart.Sub(now))})
+		return
+	}
+
+	if result.Outcome == localproc.StartNotInstalled {
+		p.notInstalled = true
+		w.events.write(event{Process: p.name, Kind: eventRestartFailed, Message: "program is not installed. stop restarting until it runs again"})
+		return
+	}
+	process.Status = localproc.StatusRunning
+	w.events.write(event{Process: p.name, Kind: eventRestarted,
+		Message: fmt.Sprintf("pid %d (%d restarts within %s)", result.Pid, len(p.restarts), w.options.CrashWindow)})
+}
+
+func pruneBefore(times []time.Time, limit time.Time) []time.Time {
+	pruned := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		if t.After(limit) {
+			pruned = append(pruned, t)
+		}
+	}
+	return pruned
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 1:40
+ */
+
+package main
+
+import (
+	"github.com/fatima-go/fatima-cmd/localproc"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type eventRecorder struct {
+	kinds []string
+}
+
+func (r *eventRecorder) write(e event) {
+	r.kinds = append(r.kinds, e.Kind)
+}
+
+func TestWatcherBackoffAndCrashLoop(t *testing.T) {
+	recorder := &eventRecorder{}
+	options := watchOptions{InitialBackoff: 5 * time.Second, MaxBackoff: 20 * time.Second, CrashWindow: time.Minute, MaxRestarts: 3}
+	w := newWatcher([]string{"juno"}, options, recorder)
+
+	now := time.Date(2026, 10, 20, 1, 0, 0, 0, time.Local)
+	status := localproc.StatusStale
+	starts := 0
+	w.now = func() time.Time { return now }
+	w.lookup = func(proc string) (localproc.Process, error) {
+		return localproc.Process{Name: proc, Pid: 100, Status: status}, nil
+	}
+	w.start = func(proc string, options localproc.StartOptions) (localproc.StartResult, error) {
+		starts++
+		return localproc.StartResult{Name: proc, Pid: 100 + starts, Outcome: localproc.StartStarted}, nil
+	}
+
+	// 처음 발견하면 종료 중일 수 있으므로 다음 확인때 재기동한다
+	w.check()
+	assert.Equal(t, 0, starts)
+	w.check()
+	assert.Equal(t, 1, starts)
+	assert.Equal(t, []string{eventDead, eventRestarted}, recorder.kinds)
+
+	// backoff(5s) 이전에는 재기동하지 않는다
+	now = now.Add(3 * time.Second)
+	w.check()
+	assert.Equal(t, 1, starts)
+
+	now = now.Add(3 * time.Second)
+	w.check()
+	assert.Equal(t, 2, starts)
+
+	// 두번째 backoff 는 10s
+	now = now.Add(6 * time.Second)
+	w.check()
+	assert.Equal(t, 2, starts)
+	now = now.Add(5 * time.Second)
+	w.check()
+	assert.Equal(t, 3, starts)
+
+	// crash window 안에 3번 재기동했으므로 crash loop 로 판단한다
+	now = now.Add(20 * time.Second)
+	w.check()
+	assert.Equal(t, 3, starts)
+	assert.Equal(t, eventCrashLoop, recorder.kinds[len(recorder.kinds)-1])
+
+	now = now.Add(time.Hour)
+	w.check()
+	assert.Equal(t, 3, starts)
+
+	// 수동으로 기동하면 crash loop 가 해제되고 backoff 가 초기화된다
+	status = localproc.StatusRunning
+	w.check()
+	assert.Equal(t, eventRecovered, recorder.kinds[len(recorder.kinds)-1])
+	assert.Equal(t, options.InitialBackoff, w.programs[0].backoff)
+
+	status = localproc.StatusStale
+	w.check()
+	w.check()
+	assert.Equal(t, 4, starts)
+}
+
+func TestWatcherIgnoreStopped(t *testing.T) {
+	recorder := &eventRecorder{}
+	options := watchOptions{InitialBackoff: time.Second, MaxBackoff: time.Second, CrashWindow: time.Minute, MaxRestarts: 3}
+	w := newWatcher([]string{"juno"}, options, recorder)
+
+	status := localproc.StatusRunning
+	w.lookup = func(proc string) (localproc.Process, error) {
+		return localproc.Process{Name: proc, Status: status}, nil
+	}
+	w.start = func(proc string, options localproc.StartOptions) (localproc.StartResult, error) {
+		t.Fatalf("stopped process must not be restarted")
+		return localproc.StartResult{}, nil
+	}
+
+	w.check()
+	status = localproc.StatusNotRunning
+	w.check()
+	w.check()
+	assert.Equal(t, []string{eventStopped}, recorder.kinds)
+}
+
+func TestWatcherStopRace(t *testing.T) {
+	recorder := &eventRecorder{}
+	options := watchOptions{InitialBackoff: time.Second, MaxBackoff: time.Second, CrashWindow: time.Minute, MaxRestarts: 3}
+	w := newWatcher([]string{"juno"}, options, recorder)
+
+	// stopro 가 프로세스를 종료한 후 pid 파일을 삭제하기 전에 확인한 경우
+	statusList := []localproc.Status{localproc.StatusRunning, localproc.StatusStale, localproc.StatusNotRunning}
+	w.lookup = func(proc string) (localproc.Process, error) {
+		status := statusList[0]
+		if len(statusList) > 1 {
+			statusList = statusList[1:]
+		}
+		return localproc.Process{Name: proc, Pid: 100, Status: status}, nil
+	}
+	w.start = func(proc string, options localproc.StartOptions) (localproc.StartResult, error) {
+		t.Fatalf("process being stopped must not be restarted")
+		return localproc.StartResult{}, nil
+	}
+
+	w.check()
+	w.check()
+	w.check()
+	w.check()
+	assert.Equal(t, []string{eventStopped}, recorder.kinds)
+}
+
+func TestWatcherNotInstalled(t *testing.T) {
+	recorder := &eventRecorder{}
+	options := watchOptions{InitialBackoff: time.Second, MaxBackoff: time.Second, CrashWindow: time.Minute, MaxRestarts: 3}
+	w := newWatcher([]string{"juno"}, options, recorder)
+
+	now := time.Date(2026, 10, 20, 1, 0, 0, 0, time.Local)
+	starts := 0
+	w.now = func() time.Time { return now }
+	w.lookup = func(proc string) (localproc.Process, error) {
+		return localproc.Process{Name: proc, Pid: 100, Status: localproc.StatusStale}, nil
+	}
+	w.start = func(proc string, options localproc.StartOptions) (localproc.StartResult, error) {
+		starts++
+		return localproc.StartResult{Name: proc, Outcome: localproc.StartNotInstalled}, nil
+	}
+
+	w.check()
+	w.check()
+	assert.Equal(t, 1, starts)
+
+	// 설치되지 않은 프로그램은 다시 재기동하지 않는다
+	now = now.Add(time.Hour)
+	w.check()
+	w.check()
+	assert.Equal(t, 1, starts)
+	assert.Equal(t, eventRestartFailed, recorder.kinds[len(recorder.kinds)-1])
+}
@@ -0,0 +1,274 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 1:10
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/config"
+	"github.com/fatima-go/fatima-cmd/localproc"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var usage = `usage: %s [options] [program...]
+
+restart all or selected opm programs. programs are stopped in reverse order and started in order
+
+optional arguments:
+  -y			yes all
+  -t			timeout for graceful shutdown before SIGKILL (default 10s)
+  -st			timeout for each readiness check while starting (default 30s)
+  -ports		ports to check readiness. e.g) jupiter:9190,juno:9180. empty to skip
+  --quiet-slack		deactivate saturn slack webhook during restart and restore it after
+
+example :
+
+restartro -y			: restart all opm programs
+restartro juno saturn		: restart juno and saturn
+restartro -y --quiet-slack	: restart all opm programs without slack notification
+`
+
+const (
+	resultSkipped = "SKIPPED"
+	resultManual  = "MANUAL"
+)
+
+// 테스트에서 교체할 수 있도록 변수로 둔다
+var (
+	stopProcess  = localproc.Stop
+	startProcess = localproc.Start
+)
+
+type commandFlags struct {
+	Yes          bool
+	StopTimeout  time.Duration
+	StartTimeout time.Duration
+	Ports        string
+	QuietSlack   bool
+	Args         []string
+}
+
+func main() {
+	if len(os.Getenv(share.EnvFatimaHome)) == 0 {
+		fmt.Printf("env %s missing\n", share.EnvFatimaHome)
+		return
+	}
+
+	flags := buildCommandFlags()
+
+	ports, err := localproc.ParsePorts(flags.Ports)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	opmPrograms, err := config.LoadOpmPrograms(os.Getenv(share.EnvFatimaHome))
+	if err != nil {
+		fmt.Printf("%s. use default opm programs\n", err.Error())
+	}
+
+	targets, err := selectPrograms(opmPrograms, flags.Args)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if !flags.Yes && !share.AskYes(fmt.Sprintf("restart %s?", strings.Join(programNames(targets), ", "))) {
+		return
+	}
+
+	fmt.Printf("RESTARTING OPM PROGRAMS...\n")
+	if !restartWithSlack(targets, ports, flags) {
+		os.Exit(1)
+	}
+}
+
+// restartWithSlack quiet-slack 옵션이 있으면 재시작하는 동안 slack webhook 을 비활성화하고 끝나면 원래 상태로 되돌린다.
+// 재시작 도중 SIGINT, SIGTERM 을 받은 경우에도 되돌린다
+func restartWithSlack(targets []config.ProcessItem, ports map[string]int, flags commandFlags) (ok bool) {
+	if !flags.QuietSlack {
+		return restart(targets, ports, flags)
+	}
+
+	previous, err := share.SetSlackActive(false)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		return false
+	}
+	fmt.Printf("slack webhook deactivated\n")
+
+	var once sync.Once
+	restore := func() bool {
+		restored := true
+		once.Do(func() {
+			err := share.RestoreSlackActive(previous)
+			if err != nil {
+				fmt.Printf("fail to restore slack webhook : %s\n", err.Error())
+				restored = false
+				return
+			}
+			fmt.Printf("slack webhook restored\n")
+		})
+		return restored
+	}
+
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case s := <-sig:
+			fmt.Printf("signal %s received\n", s)
+			restore()
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+
+	defer func() {
+		signal.Stop(sig)
+		close(done)
+		if !restore() {
+			ok = false
+		}
+	}()
+
+	return restart(targets, ports, flags)
+}
+
+// selectPrograms 지정된 프로그램을 fatima-package.yaml 순서대로 리턴한다. 지정하지 않으면 모든 프로그램을 리턴한다
+func selectPrograms(opmPrograms []config.ProcessItem, names []string) ([]config.ProcessItem, error) {
+	if len(names) == 0 {
+		return opmPrograms, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range names {
+		found := false
+		for _, p := range opmPrograms {
+			if strings.ToLower(name) == strings.ToLower(p.Name) {
+				selected[p.Name] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s is not opm program", name)
+		}
+	}
+
+	targets := make([]config.ProcessItem, 0)
+	for _, p := range opmPrograms {
+		if selected[p.Name] {
+			targets = append(targets, p)
+		}
+	}
+	return targets, nil
+}
+
+// restart 프로그램을 역순으로 종료한 후 순서대로 기동한다.
+// startmode 가 manual 인 프로그램은 명시적으로 지정했거나 재시작 전에 실행중이었던 경우에만 기동한다.
+// 종료에 실패한 프로그램은 기동하지 않지만 나머지 프로그램은 모두 기동한 후 실패를 리턴한다
+func restart(targets []config.ProcessItem, ports map[string]int, flags commandFlags) bool {
+	explicit := len(flags.Args) > 0
+	failed := false
+	stopFailed := make(map[string]bool)
+	wasRunning := make(map[string]bool)
+	data := make([][]string, 0)
+
+	for i := len(targets) - 1; i >= 0; i-- {
+		p := targets[i].Name
+		fmt.Printf("stopping process %s\n", p)
+		result, err := stopProcess(p, flags.StopTimeout)
+		wasRunning[p] = result.Outcome == localproc.StopTerminated || result.Outcome == localproc.StopKilled
+		if err != nil {
+			failed = true
+			stopFailed[p] = true
+			data = append(data, []string{p, "stop", pidString(result.Pid), result.Outcome.String(), err.Error()})
+			continue
+		}
+		data = append(data, []string{p, "stop", pidString(result.Pid), result.Outcome.String(), result.Elapsed.String()})
+	}
+
+	for _, program := range targets {
+		p := program.Name
+		if stopFailed[p] {
+			data = append(data, []string{p, "start", "", resultSkipped, "stop failed"})
+			continue
+		}
+		if !program.IsAutoStart() && !explicit && !wasRunning[p] {
+			data = append(data, []string{p, "start", "", resultManual, "startmode is manual"})
+			continue
+		}
+
+		fmt.Printf("starting process %s\n", p)
+		result, err := startProcess(p, localproc.StartOptions{Timeout: flags.StartTimeout, Port: ports[p]})
+		message := ""
+		if err != nil {
+			failed = true
+			message = err.Error()
+		}
+		data = append(data, []string{p, "start", pidString(result.Pid), result.Outcome.String(), message})
+	}
+
+	share.PrintTable([]string{"process", "step", "pid", "result", "message"}, data)
+	return !failed
+}
+
+func programNames(programs []config.ProcessItem) []string {
+	names := make([]string, 0, len(programs))
+	for _, p := range programs {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func pidString(pid int) string {
+	if pid > 0 {
+		return strconv.Itoa(pid)
+	}
+	return ""
+}
+
+func buildCommandFlags() commandFlags {
+	cmdFlags := commandFlags{}
+
+	flag.Usage = func() {
+		fmt.Printf(usage, os.Args[0])
+	}
+	flag.BoolVar(&cmdFlags.Yes, "y", false, "yes all")
+	flag.DurationVar(&cmdFlags.StopTimeout, "t", 10*time.Second, "timeout for graceful shutdown before SIGKILL")
+	flag.DurationVar(&cmdFlags.StartTimeout, "st", 30*time.Second, "timeout for each readiness check")
+	flag.StringVar(&cmdFlags.Ports, "ports", localproc.DefaultPorts, localproc.PortsUsage)
+	flag.BoolVar(&cmdFlags.QuietSlack, "quiet-slack", false, "deactivate slack webhook during restart")
+
+	flag.Parse()
+
+	cmdFlags.Args = flag.Args()
+	return cmdFlags
+}
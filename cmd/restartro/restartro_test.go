@@ -0,0 +1,125 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오후 5:10
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/config"
+	"github.com/fatima-go/fatima-cmd/localproc"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var samplePrograms = []config.ProcessItem{
+	{Name: "jupiter"},
+	{Name: "juno"},
+	{Name: "saturn"},
+	{Name: "opmhelper", Startmode: config.StartModeManual},
+}
+
+// fakeProcesses stop, start 호출 순서를 기록한다. running 에 없는 프로세스는 실행중이 아닌 것으로 처리한다
+type fakeProcesses struct {
+	calls     []string
+	running   map[string]bool
+	stopError map[string]bool
+	options   map[string]localproc.StartOptions
+}
+
+func newFakeProcesses(t *testing.T, running ...string) *fakeProcesses {
+	f := &fakeProcesses{running: make(map[string]bool), stopError: make(map[string]bool), options: make(map[string]localproc.StartOptions)}
+	for _, p := range running {
+		f.running[p] = true
+	}
+
+	stopProcess = func(proc string, timeout time.Duration) (localproc.StopResult, error) {
+		f.calls = append(f.calls, "stop "+proc)
+		if f.stopError[proc] {
+			return localproc.StopResult{Name: proc, Pid: 100}, fmt.Errorf("process %s is still alive", proc)
+		}
+		if !f.running[proc] {
+			return localproc.StopResult{Name: proc, Outcome: localproc.StopNotRunning}, nil
+		}
+		f.running[proc] = false
+		return localproc.StopResult{Name: proc, Pid: 100, Outcome: localproc.StopTerminated}, nil
+	}
+	startProcess = func(proc string, options localproc.StartOptions) (localproc.StartResult, error) {
+		f.calls = append(f.calls, "start "+proc)
+		f.options[proc] = options
+		f.running[proc] = true
+		return localproc.StartResult{Name: proc, Pid: 200, Outcome: localproc.StartStarted}, nil
+	}
+	t.Cleanup(func() {
+		stopProcess = localproc.Stop
+		startProcess = localproc.Start
+	})
+	return f
+}
+
+func TestRestart(t *testing.T) {
+	f := newFakeProcesses(t, "jupiter", "juno", "saturn")
+	flags := commandFlags{StopTimeout: time.Second, StartTimeout: 3 * time.Second}
+
+	// 역순으로 모두 종료한 후 순서대로 기동한다. 실행중이 아니었던 manual 프로그램은 기동하지 않는다
+	assert.True(t, restart(samplePrograms, map[string]int{"jupiter": 9190}, flags))
+	assert.Equal(t, []string{
+		"stop opmhelper", "stop saturn", "stop juno", "stop jupiter",
+		"start jupiter", "start juno", "start saturn",
+	}, f.calls)
+	assert.Equal(t, localproc.StartOptions{Timeout: 3 * time.Second, Port: 9190}, f.options["jupiter"])
+	assert.Equal(t, 0, f.options["juno"].Port)
+}
+
+func TestRestartManual(t *testing.T) {
+	f := newFakeProcesses(t, "opmhelper")
+	assert.True(t, restart(samplePrograms[3:], nil, commandFlags{}))
+	assert.Equal(t, []string{"stop opmhelper", "start opmhelper"}, f.calls)
+
+	// 명시적으로 지정하면 실행중이 아니었어도 기동한다
+	f = newFakeProcesses(t)
+	assert.True(t, restart(samplePrograms[3:], nil, commandFlags{Args: []string{"opmhelper"}}))
+	assert.Equal(t, []string{"stop opmhelper", "start opmhelper"}, f.calls)
+}
+
+func TestRestartStopFailed(t *testing.T) {
+	f := newFakeProcesses(t, "jupiter", "juno", "saturn")
+	f.stopError["juno"] = true
+
+	// 종료에 실패한 프로그램만 기동하지 않고 나머지는 모두 재시작한 후 실패를 리턴한다
+	assert.False(t, restart(samplePrograms[:3], nil, commandFlags{}))
+	assert.Equal(t, []string{
+		"stop saturn", "stop juno", "stop jupiter",
+		"start jupiter", "start saturn",
+	}, f.calls)
+}
+
+func TestSelectPrograms(t *testing.T) {
+	targets, err := selectPrograms(samplePrograms, []string{"Saturn", "jupiter"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"jupiter", "saturn"}, programNames(targets))
+
+	targets, err = selectPrograms(samplePrograms, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(targets))
+
+	_, err = selectPrograms(samplePrograms, []string{"mypgm"})
+	assert.NotNil(t, err)
+}
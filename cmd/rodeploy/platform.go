@@ -24,8 +24,10 @@ import (
 	"archive/zip"
 	"encoding/json"
 	"fmt"
+	"github.com/fatima-go/fatima-cmd/extract"
 	"github.com/fatima-go/fatima-cmd/share"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -73,11 +75,17 @@ func reformArtifact(flags share.FatimaCmdFlags, originFarFile string, platform s
 		return "", fmt.Errorf("fail to create tmp dir : %s", err.Error())
 	}
 
-	err = unzip(originFarFile, workingDir)
+	err = extract.Zip(originFarFile, workingDir, farExtractOptions())
 	if err != nil {
 		return "", fmt.Errorf("fail to unzip : %s", err.Error())
 	}
 
+	// 원본 far 의 zip header(mode, 수정시간, comment)를 그대로 유지하기 위해 읽어둔다
+	origin, err := readZipHeaders(originFarFile)
+	if err != nil {
+		return "", err
+	}
+
 	// copy platform target bin to base dir
 	platformBaseDir := filepath.Join(workingDir, PlatformDirName)
 	platformTargetDir := filepath.Join(platformBaseDir, platform)
@@ -96,6 +104,7 @@ func reformArtifact(flags share.FatimaCmdFlags, originFarFile string, platform s
 			return "", fmt.Errorf("fail to copy %s : %s", srcFile, err.Error())
 		}
 		_ = os.Chmod(dstFile, 0755)
+		origin.moveHeader(path.Join(PlatformDirName, platform, file.Name()), file.Name())
 	}
 
 	// remove all platform directories
@@ -106,7 +115,14 @@ func reformArtifact(flags share.FatimaCmdFlags, originFarFile string, platform s
 
 	// zip again
 	artifactFile := filepath.Join(workingDir, exposeName)
-	return artifactFile, zipArtifact(workingDir, artifactFile, executableBinNameList)
+	return artifactFile, zipArtifact(workingDir, artifactFile, executableBinNameList, origin)
+}
+
+// farExtractOptions far 의 entry 는 '/' 로 시작하므로 해제 디렉토리 기준으로 해제한다
+func farExtractOptions() extract.Options {
+	opt := extract.DefaultOptions()
+	opt.TrimRoot = true
+	return opt
 }
 
 func markDeployUser(flags share.FatimaCmdFlags, workingDir string) {
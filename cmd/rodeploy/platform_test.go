@@ -0,0 +1,119 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 3:02
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var sampleEntries = []struct {
+	name string
+	mode os.FileMode
+	body string
+}{
+	{"/", os.ModeDir | 0755, ""},
+	{"/deployment.json", 0644, `{"process":"sample","build":{"time":"2023-09-12 15:21:00","user":"builder"}}`},
+	{"/run.sh", 0755, "#!/bin/sh\n"},
+	{"/platform/", os.ModeDir | 0755, ""},
+	{"/platform/linux_amd64/", os.ModeDir | 0755, ""},
+	{"/platform/linux_amd64/sample", 0750, "linux binary"},
+	{"/platform/darwin_arm64/", os.ModeDir | 0755, ""},
+	{"/platform/darwin_arm64/sample", 0750, "darwin binary"},
+}
+
+func buildSampleFar(t *testing.T, modified time.Time) string {
+	farFile := filepath.Join(t.TempDir(), "sample.far")
+	f, err := os.Create(farFile)
+	if err != nil {
+		t.Fatalf("fail to create far : %s", err.Error())
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	defer zw.Close()
+	_ = zw.SetComment("sample far")
+	for _, e := range sampleEntries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: modified, Comment: e.name}
+		header.SetMode(e.mode)
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("fail to create entry %s : %s", e.name, err.Error())
+		}
+		_, _ = w.Write([]byte(e.body))
+	}
+	return farFile
+}
+
+func reformAndRead(t *testing.T, farFile string) []byte {
+	flags := share.FatimaCmdFlags{Username: "deployer"}
+	artifact, err := reformArtifact(flags, farFile, "linux_amd64")
+	if err != nil {
+		t.Fatalf("fail to reform : %s", err.Error())
+	}
+	defer os.RemoveAll(filepath.Dir(artifact))
+
+	data, err := os.ReadFile(artifact)
+	if err != nil {
+		t.Fatalf("fail to read reformed far : %s", err.Error())
+	}
+	return data
+}
+
+func TestReformArtifact(t *testing.T) {
+	modified := time.Date(2023, 9, 12, 15, 21, 0, 0, time.UTC)
+	farFile := buildSampleFar(t, modified)
+
+	first := reformAndRead(t, farFile)
+	second := reformAndRead(t, farFile)
+	assert.True(t, bytes.Equal(first, second), "reformed far should be reproducible")
+
+	reader, err := zip.NewReader(bytes.NewReader(first), int64(len(first)))
+	if err != nil {
+		t.Fatalf("fail to read reformed far : %s", err.Error())
+	}
+	assert.Equal(t, "sample far", reader.Comment)
+
+	entries := make(map[string]*zip.File)
+	for _, f := range reader.File {
+		entries[f.Name] = f
+	}
+
+	_, ok := entries["/platform/"]
+	assert.False(t, ok)
+
+	script := entries["/run.sh"]
+	assert.NotNil(t, script)
+	assert.Equal(t, os.FileMode(0755), script.Mode())
+	assert.Equal(t, modified.Unix(), script.Modified.Unix())
+	assert.Equal(t, "/run.sh", script.Comment)
+
+	bin := entries["/sample"]
+	assert.NotNil(t, bin)
+	assert.Equal(t, os.FileMode(0751), bin.Mode())
+	assert.Equal(t, modified.Unix(), bin.Modified.Unix())
+}
@@ -24,6 +24,7 @@ import (
 	"flag"
 	"fmt"
 	. "github.com/fatima-go/fatima-cmd/domain"
+	"github.com/fatima-go/fatima-cmd/far"
 	"github.com/fatima-go/fatima-cmd/jupiter"
 	"github.com/fatima-go/fatima-cmd/share"
 	"os"
@@ -99,12 +100,19 @@ func main() {
 			return
 		}
 
-		if !hasPlatform(farArtifactFile, targetPlatform) {
+		if !hasPlatform(farArtifactFile, targetPlatform.String()) {
 			fmt.Printf("far(%s) doesn't support platform %s\n", farArtifactFile, targetPlatform)
 			return
 		}
 
-		farArtifactFile, err = reformArtifact(fatimaFlags, farArtifactFile, targetPlatform)
+		// 디렉토리 이름만 믿지 않고 실제 바이너리 헤더가 타겟 플랫폼과 일치하는지 검사한다
+		err = far.VerifyPlatform(farArtifactFile, targetPlatform)
+		if err != nil {
+			fmt.Printf("far(%s) refused : %s\n", farArtifactFile, err.Error())
+			return
+		}
+
+		farArtifactFile, err = reformArtifact(fatimaFlags, farArtifactFile, targetPlatform.String())
 		if err != nil {
 			fmt.Printf("fail to reform artifact for target platform %s : %s", targetPlatform, err.Error())
 			return
@@ -123,53 +131,53 @@ func main() {
 	}
 }
 
-func findPlatform(ropackResp RopackResp, flags share.FatimaCmdFlags, group string) (string, error) {
+func findPlatform(ropackResp RopackResp, flags share.FatimaCmdFlags, group string) (PlatformResp, error) {
 	// flags.UserPackage 가 존재할 경우 해당 HOST 를 찾는다
 	if len(flags.UserPackage) > 0 {
 		deploy, err := ropackResp.Summary.FindDeployByHost(flags.UserPackage)
 		if err != nil {
-			return "", err
+			return PlatformResp{}, err
 		}
 		fmt.Printf("%s target %s::%s\n", time.Now().Format(yyyyMMddHHmmss), deploy.Host, deploy.Platform)
-		return deploy.Platform.String(), nil
+		return deploy.Platform, nil
 	}
 
 	// 디플로이 정보가 아예 없다면 에러 처리한다
 	if ropackResp.Summary.IsEmptyDeployment() {
-		return "", fmt.Errorf("deployment is empty")
+		return PlatformResp{}, fmt.Errorf("deployment is empty")
 	}
 
 	// 단 한개의 호스트만 존재할 경우 해당 호스트를 넘겨준다
 	if !ropackResp.Summary.HasMultipleHost() {
 		deploy, err := ropackResp.Summary.GetFirstDeploymentHost()
 		if err != nil {
-			return "", err
+			return PlatformResp{}, err
 		}
 
 		fmt.Printf("%s target %s:%s\n", time.Now().Format(yyyyMMddHHmmss), deploy.Host, deploy.Platform)
-		return deploy.Platform.String(), nil
+		return deploy.Platform, nil
 	}
 
 	// flags.UserPackage 가 비어 있고 group이 존재할 경우 해당 그룹의 첫번째 호스트를 찾는다
 	if len(group) > 0 {
 		deployment, err := ropackResp.Summary.GetDeploymentByGroup(group)
 		if err != nil {
-			return "", err
+			return PlatformResp{}, err
 		}
 		if len(deployment.Deploy) == 0 {
-			return "", fmt.Errorf("empty deploy for group %s", group)
+			return PlatformResp{}, fmt.Errorf("empty deploy for group %s", group)
 		}
 
 		fmt.Printf("%s target group %s::%s\n", time.Now().Format(yyyyMMddHHmmss), deployment.GroupName, deployment.Deploy[0].Platform)
-		return deployment.Deploy[0].Platform.String(), nil
+		return deployment.Deploy[0].Platform, nil
 	}
 
 	// flags.UserPackage, group이 모두 비어 있을 경우 같은 IP 를 찾는다
 	deployment, err := ropackResp.Summary.FindDeployByLocalIpaddress()
 	if err != nil {
-		return "", err
+		return PlatformResp{}, err
 	}
 
 	fmt.Printf("%s target %s::%s\n", time.Now().Format(yyyyMMddHHmmss), deployment.Host, deployment.Platform)
-	return deployment.Platform.String(), nil
+	return deployment.Platform, nil
 }
@@ -30,53 +30,6 @@ import (
 	"time"
 )
 
-func unzip(sourceFarFile, destDir string) error {
-	archive, err := zip.OpenReader(sourceFarFile)
-	if err != nil {
-		return fmt.Errorf("fail to open zip reader %s : %s", sourceFarFile, err.Error())
-	}
-	defer archive.Close()
-
-	for _, f := range archive.File {
-		filePath := filepath.Join(destDir, f.Name)
-
-		if f.Name == "/" {
-			continue
-		}
-
-		if !strings.HasPrefix(filePath, filepath.Clean(destDir)+string(os.PathSeparator)) {
-			return fmt.Errorf("invalid file path")
-		}
-
-		if f.FileInfo().IsDir() {
-			err = os.MkdirAll(filePath, os.ModePerm)
-			if err != nil {
-				return err
-			}
-			continue
-		}
-
-		dstFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return fmt.Errorf("fail to open %s : %s", filePath, err.Error())
-		}
-
-		fileInArchive, err := f.Open()
-		if err != nil {
-			return fmt.Errorf("fail to open %s file : %s", f.Name, err.Error())
-		}
-
-		if _, err := io.Copy(dstFile, fileInArchive); err != nil {
-			return fmt.Errorf("fail to copy %s : %s", dstFile.Name(), err.Error())
-		}
-
-		dstFile.Close()
-		fileInArchive.Close()
-	}
-
-	return nil
-}
-
 func copyFile(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
@@ -105,7 +58,52 @@ type fileMeta struct {
 	IsDir bool
 }
 
-func zipArtifact(baseDir, artifactFile string, executableBinNameList []string) error {
+// zipHeaders 원본 far 의 entry header 들. reform 된 far 가 원본의 mode, 수정시간, comment 를 유지하도록 사용한다
+type zipHeaders struct {
+	headers  map[string]zip.FileHeader
+	comment  string
+	modified time.Time // 원본에 없는 entry 에 사용할 시간 (원본 entry 중 가장 최근 시간)
+}
+
+func readZipHeaders(zipfile string) (zipHeaders, error) {
+	z := zipHeaders{headers: make(map[string]zip.FileHeader)}
+
+	archive, err := zip.OpenReader(zipfile)
+	if err != nil {
+		return z, fmt.Errorf("fail to open zip reader %s : %s", zipfile, err.Error())
+	}
+	defer archive.Close()
+
+	z.comment = archive.Comment
+	for _, f := range archive.File {
+		z.headers[zipEntryKey(f.Name)] = f.FileHeader
+		if f.Modified.After(z.modified) {
+			z.modified = f.Modified
+		}
+	}
+
+	return z, nil
+}
+
+// moveHeader from entry 의 header 를 to entry 의 것으로 사용한다
+func (z zipHeaders) moveHeader(from, to string) {
+	header, ok := z.headers[zipEntryKey(from)]
+	if !ok {
+		return
+	}
+	z.headers[zipEntryKey(to)] = header
+}
+
+func (z zipHeaders) find(name string) (zip.FileHeader, bool) {
+	header, ok := z.headers[zipEntryKey(name)]
+	return header, ok
+}
+
+func zipEntryKey(name string) string {
+	return strings.Trim(filepath.ToSlash(name), "/")
+}
+
+func zipArtifact(baseDir, artifactFile string, executableBinNameList []string, origin zipHeaders) error {
 	var files []fileMeta
 	err := filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
 		files = append(files, fileMeta{Path: path, IsDir: info.IsDir()})
@@ -124,6 +122,11 @@ func zipArtifact(baseDir, artifactFile string, executableBinNameList []string) e
 	zw := zip.NewWriter(z)
 	defer zw.Close()
 
+	err = zw.SetComment(origin.comment)
+	if err != nil {
+		return err
+	}
+
 	for _, f := range files {
 		path := f.Path
 
@@ -137,7 +140,7 @@ func zipArtifact(baseDir, artifactFile string, executableBinNameList []string) e
 			path = fmt.Sprintf("%s%c", path, os.PathSeparator)
 		}
 
-		err = copyIntoZip(zw, path, f, executableBinNameList)
+		err = copyIntoZip(zw, path, f, executableBinNameList, origin)
 		if err != nil {
 			return err
 		}
@@ -146,17 +149,28 @@ func zipArtifact(baseDir, artifactFile string, executableBinNameList []string) e
 	return nil
 }
 
-func copyIntoZip(zw *zip.Writer, path string, f fileMeta, executableBinNameList []string) error {
+func copyIntoZip(zw *zip.Writer, path string, f fileMeta, executableBinNameList []string, origin zipHeaders) error {
 	header := &zip.FileHeader{
 		Name:     path,
 		Method:   zip.Deflate,
-		Modified: time.Now(),
+		Modified: origin.modified,
+	}
+
+	originHeader, found := origin.find(path)
+	if found {
+		header.Modified = originHeader.Modified
+		header.Comment = originHeader.Comment
+		header.SetMode(originHeader.Mode())
 	}
 
 	baseName := filepath.Base(path)
 	for _, executableBin := range executableBinNameList {
 		if strings.Compare(baseName, executableBin) == 0 {
-			header.SetMode(0755)
+			if found {
+				header.SetMode(originHeader.Mode() | 0111)
+			} else {
+				header.SetMode(0755)
+			}
 			break
 		}
 	}
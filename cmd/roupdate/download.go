@@ -0,0 +1,292 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 2:20
+ */
+
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	partialSuffix         = ".part"
+	defaultRetryWait      = 2 * time.Second
+	progressInterval      = 500 * time.Millisecond
+	downloadDialTimeout   = 10 * time.Second
+	responseHeaderTimeout = 30 * time.Second
+)
+
+type DownloadOptions struct {
+	Proxy     string // 비어있으면 HTTPS_PROXY, HTTP_PROXY, NO_PROXY 환경변수를 사용한다
+	Insecure  bool   // tls 인증서 검증을 하지 않는다
+	Retries   int    // 실패시 재시도 횟수
+	RetryWait time.Duration
+	Progress  io.Writer // nil 이면 진행상황을 출력하지 않는다
+}
+
+type Downloader struct {
+	client  *http.Client
+	options DownloadOptions
+}
+
+// permanentError 재시도해도 성공할 수 없는 에러
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func NewDownloader(options DownloadOptions) (*Downloader, error) {
+	proxy := http.ProxyFromEnvironment
+	if len(options.Proxy) > 0 {
+		proxyUrl, err := url.Parse(options.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url %s : %s", options.Proxy, err.Error())
+		}
+		proxy = http.ProxyURL(proxyUrl)
+	}
+
+	if options.RetryWait <= 0 {
+		options.RetryWait = defaultRetryWait
+	}
+
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout: downloadDialTimeout,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: options.Insecure},
+	}
+
+	// redirect 는 http.Client 기본 정책(최대 10번)을 따른다. Range 헤더는 redirect 된 요청에도 전달된다
+	return &Downloader{client: &http.Client{Transport: transport}, options: options}, nil
+}
+
+// DownloadFileName url path 의 마지막 이름을 리턴한다
+func DownloadFileName(rawUrl string) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %s : %s", rawUrl, err.Error())
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return "", fmt.Errorf("cannot find file name in url %s", rawUrl)
+	}
+	return name, nil
+}
+
+// Download rawUrl 을 dest 로 다운로드한다. 받는 중에는 dest.part 에 기록하고
+// 연결이 끊어지면 Range 요청으로 이어받는다
+func (d *Downloader) Download(rawUrl string, dest string) error {
+	partial := dest + partialSuffix
+
+	var err error
+	for attempt := 0; attempt <= d.options.Retries; attempt++ {
+		if attempt > 0 {
+			fmt.Printf("retry download (%d/%d) after %s : %s\n", attempt, d.options.Retries, d.options.RetryWait, err.Error())
+			time.Sleep(d.options.RetryWait)
+		}
+
+		err = d.fetch(rawUrl, partial)
+		if err == nil {
+			return os.Rename(partial, dest)
+		}
+
+		var permanent permanentError
+		if errors.As(err, &permanent) {
+			break
+		}
+	}
+
+	return fmt.Errorf("fail to download %s : %s", rawUrl, err.Error())
+}
+
+// fetch partial 파일의 크기부터 이어서 받는다
+func (d *Downloader) fetch(rawUrl string, partial string) error {
+	var offset int64
+	if fi, err := os.Stat(partial); err == nil {
+		offset = fi.Size()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawUrl, nil)
+	if err != nil {
+		return permanentError{err}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flag := os.O_CREATE | os.O_WRONLY
+	total := resp.ContentLength
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// 서버가 Range 를 지원하지 않으면 처음부터 다시 받는다
+		flag |= os.O_TRUNC
+		offset = 0
+	case http.StatusPartialContent:
+		start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			_ = os.Remove(partial)
+			return fmt.Errorf("unexpected content range %q for offset %d", resp.Header.Get("Content-Range"), offset)
+		}
+		flag |= os.O_APPEND
+		total = size
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 이미 모두 받은 경우
+		_, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err == nil && size == offset {
+			return nil
+		}
+		_ = os.Remove(partial)
+		return fmt.Errorf("range not satisfiable for offset %d", offset)
+	default:
+		err := fmt.Errorf("http response status %s", resp.Status)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests {
+			return err
+		}
+		return permanentError{err}
+	}
+
+	file, err := os.OpenFile(partial, flag, 0644)
+	if err != nil {
+		return permanentError{fmt.Errorf("fail to open %s : %s", partial, err.Error())}
+	}
+	defer file.Close()
+
+	var writer io.Writer = file
+	var progress *progressWriter
+	if d.options.Progress != nil {
+		progress = newProgressWriter(d.options.Progress, path.Base(partial[:len(partial)-len(partialSuffix)]), offset, total)
+		writer = io.MultiWriter(file, progress)
+	}
+
+	written, err := io.Copy(writer, resp.Body)
+	if progress != nil {
+		progress.finish()
+	}
+	if err != nil {
+		return fmt.Errorf("download interrupted at %d bytes : %s", offset+written, err.Error())
+	}
+	if resp.ContentLength >= 0 && written != resp.ContentLength {
+		return fmt.Errorf("download interrupted at %d bytes. expected %d bytes", offset+written, offset+resp.ContentLength)
+	}
+
+	return nil
+}
+
+// parseContentRange "bytes 100-199/200" 또는 "bytes */200" 에서 시작 위치와 전체 크기를 읽는다
+func parseContentRange(value string) (int64, int64, error) {
+	spec, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid content range %q", value)
+	}
+	rangePart, sizePart, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid content range %q", value)
+	}
+
+	size := int64(-1)
+	if sizePart != "*" {
+		s, err := strconv.ParseInt(sizePart, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid content range %q", value)
+		}
+		size = s
+	}
+
+	if rangePart == "*" {
+		return 0, size, nil
+	}
+	startPart, _, ok := strings.Cut(rangePart, "-")
+	start, err := strconv.ParseInt(startPart, 10, 64)
+	if !ok || err != nil {
+		return 0, 0, fmt.Errorf("invalid content range %q", value)
+	}
+	return start, size, nil
+}
+
+// progressWriter 다운로드 진행상황을 일정 간격으로 출력한다
+type progressWriter struct {
+	out     io.Writer
+	name    string
+	current int64
+	total   int64
+	last    time.Time
+}
+
+// newProgressWriter total 은 이어받는 경우에도 파일 전체 크기이다
+func newProgressWriter(out io.Writer, name string, offset int64, total int64) *progressWriter {
+	return &progressWriter{out: out, name: name, current: offset, total: total}
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.current += int64(len(b))
+	if time.Since(p.last) >= progressInterval {
+		p.print()
+	}
+	return len(b), nil
+}
+
+func (p *progressWriter) print() {
+	p.last = time.Now()
+	if p.total > 0 {
+		fmt.Fprintf(p.out, "\r%s %3d%% %s / %s", p.name, p.current*100/p.total, byteSize(p.current), byteSize(p.total))
+		return
+	}
+	fmt.Fprintf(p.out, "\r%s %s", p.name, byteSize(p.current))
+}
+
+func (p *progressWriter) finish() {
+	p.print()
+	fmt.Fprintf(p.out, "\n")
+}
+
+func byteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
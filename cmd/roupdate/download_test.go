@@ -0,0 +1,175 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 2:20
+ */
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/extract"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testContent() []byte {
+	return bytes.Repeat([]byte("fatima-package "), 4096)
+}
+
+func serveContent(content []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "artifact.tar.gz", time.Time{}, bytes.NewReader(content))
+	}
+}
+
+func newTestDownloader(t *testing.T, retries int) *Downloader {
+	d, err := NewDownloader(DownloadOptions{Retries: retries, RetryWait: 10 * time.Millisecond})
+	assert.Nil(t, err)
+	return d
+}
+
+func TestDownloadRedirect(t *testing.T) {
+	content := testContent()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/raw/artifact.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/files/artifact.tar.gz", http.StatusFound)
+	})
+	mux.HandleFunc("/files/artifact.tar.gz", serveContent(content))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "artifact.tar.gz")
+	err := newTestDownloader(t, 0).Download(server.URL+"/raw/artifact.tar.gz", dest)
+	assert.Nil(t, err)
+
+	b, _ := os.ReadFile(dest)
+	assert.Equal(t, content, b)
+	_, err = os.Stat(dest + partialSuffix)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := testContent()
+	half := len(content) / 2
+	var ranges []string
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if atomic.AddInt32(&requests, 1) > 1 {
+			serveContent(content)(w, r)
+			return
+		}
+
+		// 절반만 보내고 연결을 끊는다
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		assert.Nil(t, err)
+		_, _ = fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n", len(content))
+		_, _ = buf.Write(content[:half])
+		_ = buf.Flush()
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "artifact.tar.gz")
+	err := newTestDownloader(t, 2).Download(server.URL+"/artifact.tar.gz", dest)
+	assert.Nil(t, err)
+
+	b, _ := os.ReadFile(dest)
+	assert.Equal(t, content, b)
+	assert.Equal(t, []string{"", fmt.Sprintf("bytes=%d-", half)}, ranges)
+}
+
+func TestDownloadRetry(t *testing.T) {
+	content := testContent()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/missing.tar.gz") {
+			atomic.AddInt32(&requests, 1)
+			http.NotFound(w, r)
+			return
+		}
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		serveContent(content)(w, r)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	err := newTestDownloader(t, 3).Download(server.URL+"/artifact.tar.gz", filepath.Join(dir, "artifact.tar.gz"))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// 404 는 재시도하지 않는다
+	atomic.StoreInt32(&requests, 0)
+	err = newTestDownloader(t, 3).Download(server.URL+"/missing.tar.gz", filepath.Join(dir, "missing.tar.gz"))
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestExecuteDownload(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	body := []byte("#!/bin/sh\n")
+	assert.Nil(t, tw.WriteHeader(&tar.Header{Name: "fatima-package/bin/._rodis", Mode: 0644, Size: int64(len(body)), Typeflag: tar.TypeReg}))
+	_, _ = tw.Write(body)
+	assert.Nil(t, tw.WriteHeader(&tar.Header{Name: "fatima-package/bin/rodis", Mode: 0755, Size: int64(len(body)), Typeflag: tar.TypeReg}))
+	_, _ = tw.Write(body)
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gw.Close())
+
+	server := httptest.NewServer(serveContent(buf.Bytes()))
+	defer server.Close()
+
+	origin := artifactUrl
+	artifactUrl = server.URL + "/fatima-package.linux-amd64.tar.gz"
+	defer func() { artifactUrl = origin }()
+	downloadOptions = DownloadOptions{Progress: io.Discard}
+
+	ctx := &UpdateContext{WorkingDir: t.TempDir()}
+	err := ExecuteDownload{}.Execute(ctx)
+	assert.Nil(t, err)
+
+	b, err := os.ReadFile(filepath.Join(ctx.GetPackingDir(), "bin", "rodis"))
+	assert.Nil(t, err)
+	assert.Equal(t, body, b)
+	_, err = os.Stat(filepath.Join(ctx.GetPackingDir(), "bin", "._rodis"))
+	assert.True(t, os.IsNotExist(err))
+
+	// 잘린 타르볼은 해제 단계에서 실패한다
+	truncated := httptest.NewServer(serveContent(buf.Bytes()[:buf.Len()-8]))
+	defer truncated.Close()
+	artifactUrl = truncated.URL + "/fatima-package.linux-amd64.tar.gz"
+
+	ctx = &UpdateContext{WorkingDir: t.TempDir()}
+	err = ExecuteDownload{}.Execute(ctx)
+	assert.ErrorIs(t, err, extract.ErrTruncated)
+}
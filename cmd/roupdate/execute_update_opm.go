@@ -22,32 +22,50 @@ package main
 
 import (
 	"fmt"
-	"gopkg.in/yaml.v2"
+	"github.com/fatima-go/fatima-cmd/config"
+	"github.com/fatima-go/fatima-cmd/localproc"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 )
 
+const opmDownTimeout = 30 * time.Second
+
 type ExecuteUpdateOpm struct {
+	shell func(wd, command string) error // nil 이면 ExecuteShell 을 사용한다
 }
 
 func (i ExecuteUpdateOpm) Name() string {
 	return "update opm processes"
 }
 
-func (i ExecuteUpdateOpm) Execute(jobContext *UpdateContext) error {
+func (i ExecuteUpdateOpm) Execute(jobContext *UpdateContext) (err error) {
 	targetOpmBinFiles := i.GetTargetBin(jobContext)
 	if len(targetOpmBinFiles) == 0 {
 		return fmt.Errorf("not found target opm process")
 	}
 
-	err := i.stopOpm(jobContext)
+	err = i.muteSlack(jobContext)
+	if err != nil {
+		return fmt.Errorf("fail to deactivate slack : %s", err.Error())
+	}
+	// 업데이트에 실패하더라도 slack 은 다시 활성화한다
+	defer func() {
+		slackErr := i.unmuteSlack(jobContext)
+		if slackErr != nil && err == nil {
+			err = fmt.Errorf("fail to activate slack : %s", slackErr.Error())
+		}
+	}()
+
+	err = i.stopOpm(jobContext)
 	if err != nil {
 		return fmt.Errorf("fail to stop opm : %s", err.Error())
 	}
 
-	i.WaitUntilOpmDown(jobContext)
+	err = i.WaitUntilOpmDown(jobContext, targetOpmBinFiles)
+	if err != nil {
+		return err
+	}
 
 	// copy
 	for _, file := range targetOpmBinFiles {
@@ -67,129 +85,77 @@ func (i ExecuteUpdateOpm) Execute(jobContext *UpdateContext) error {
 	return i.startOpm(jobContext)
 }
 
-func (i ExecuteUpdateOpm) WaitUntilOpmDown(jobContext *UpdateContext) error {
+func (i ExecuteUpdateOpm) execute(jobContext *UpdateContext, command string) error {
+	if i.shell != nil {
+		return i.shell(jobContext.WorkingDir, command)
+	}
+	return ExecuteShell(jobContext.WorkingDir, command)
+}
+
+// WaitUntilOpmDown opm 프로세스가 모두 종료될 때까지 pid 파일과 프로세스 생존 여부를 확인한다
+func (i ExecuteUpdateOpm) WaitUntilOpmDown(jobContext *UpdateContext, procs []string) error {
 	fmt.Printf("wait until opm process down...\n")
-	time.Sleep(time.Second * 3)
+	deadline := time.Now().Add(opmDownTimeout)
+	for _, proc := range procs {
+		for {
+			process, err := localproc.Lookup(proc)
+			if err != nil {
+				return fmt.Errorf("fail to check %s : %s", proc, err.Error())
+			}
+			if !process.IsRunning() {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("%s(pid %d) is still running after %s", proc, process.Pid, opmDownTimeout)
+			}
+			time.Sleep(localproc.PollInterval)
+		}
+	}
 	return nil
 }
 
-func (i ExecuteUpdateOpm) stopOpm(jobContext *UpdateContext) error {
-	fmt.Printf("- stop opm process\n")
-
-	// lcslack false
-	command := fmt.Sprintf("lcslack false")
-	err := ExecuteShell(jobContext.WorkingDir, command)
+func (i ExecuteUpdateOpm) muteSlack(jobContext *UpdateContext) error {
+	err := i.execute(jobContext, "lcslack false")
 	if err != nil {
 		return err
 	}
 
 	// sleep 1
 	time.Sleep(time.Second)
-
-	// stopro -y
-	command = fmt.Sprintf("stopro -y")
-	err = ExecuteShell(jobContext.WorkingDir, command)
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
 
-func (i ExecuteUpdateOpm) startOpm(jobContext *UpdateContext) error {
-	fmt.Printf("- start opm process\n")
-
-	command := fmt.Sprintf("startro -y")
-	err := ExecuteShell(jobContext.WorkingDir, command)
-	if err != nil {
-		return err
-	}
-
+func (i ExecuteUpdateOpm) unmuteSlack(jobContext *UpdateContext) error {
 	// sleep 1
 	time.Sleep(time.Second)
+	return i.execute(jobContext, "lcslack true")
+}
 
-	// lcslack true
-	command = fmt.Sprintf("lcslack true")
-	err = ExecuteShell(jobContext.WorkingDir, command)
-	if err != nil {
-		return err
-	}
+func (i ExecuteUpdateOpm) stopOpm(jobContext *UpdateContext) error {
+	fmt.Printf("- stop opm process\n")
+	return i.execute(jobContext, "stopro -y")
+}
 
-	return nil
+func (i ExecuteUpdateOpm) startOpm(jobContext *UpdateContext) error {
+	fmt.Printf("- start opm process\n")
+	return i.execute(jobContext, "startro -y")
 }
 
+// GetTargetBin fatima-package.yaml 의 OPM 그룹 프로세스 중 artifact 에 포함된 프로세스 목록을 리턴한다
 func (i ExecuteUpdateOpm) GetTargetBin(jobContext *UpdateContext) []string {
 	targetBinList := make([]string, 0)
-	procConfigYaml := filepath.Join(jobContext.FatimaHomeDir, "conf", FatimaFileProcConfig)
-	data, err := os.ReadFile(procConfigYaml)
-	if err != nil {
-		fmt.Printf("fail to open %s : %s", procConfigYaml, err.Error())
-		return targetBinList
-	}
-
-	procConfig := YamlFatimaPackageConfig{}
-	err = yaml.Unmarshal(data, &procConfig)
+	opmPrograms, err := config.LoadOpmPrograms(jobContext.FatimaHomeDir)
 	if err != nil {
-		fmt.Printf("fail to yaml unmarshal %s : %s", procConfigYaml, err.Error())
-		return targetBinList
-	}
-
-	opmGid := procConfig.GetOpmGid()
-	if opmGid < 0 {
-		fmt.Printf("not found opm gid %s : %s", procConfigYaml, err.Error())
-		return targetBinList
-	}
-
-	return procConfig.GetProcessList(opmGid)
-}
-
-const (
-	FatimaFileProcConfig = "fatima-package.yaml"
-)
-
-type YamlFatimaPackageConfig struct {
-	Groups    []GroupItem   `yaml:"group,flow"`
-	Processes []ProcessItem `yaml:"process"`
-}
-
-func (y YamlFatimaPackageConfig) GetOpmGid() int {
-	for _, item := range y.Groups {
-		if strings.ToUpper(item.Name) == "OPM" {
-			return item.Id
-		}
+		fmt.Printf("%s. use default opm programs\n", err.Error())
 	}
 
-	return -1 // not found
-}
-
-var defaultOpmProcessSet = map[string]struct{}{"jupiter": {}, "juno": {}, "saturn": {}}
-
-func (y YamlFatimaPackageConfig) GetProcessList(gid int) []string {
-	targetList := make([]string, 0)
-
-	for _, item := range y.Processes {
-		if item.Gid == gid {
-			_, ok := defaultOpmProcessSet[item.Name]
-			if ok {
-				targetList = append(targetList, item.Name)
-			}
+	for _, p := range opmPrograms {
+		artifactBin := filepath.Join(jobContext.GetPackingDir(), "app", p.Name, p.Name)
+		if _, err := os.Stat(artifactBin); err != nil {
+			continue
 		}
+		targetBinList = append(targetBinList, p.Name)
 	}
 
-	return targetList
-}
-
-type GroupItem struct {
-	Id   int    `yaml:"id"`
-	Name string `yaml:"name"`
-}
-
-type ProcessItem struct {
-	Gid       int    `yaml:"gid"`
-	Name      string `yaml:"name"`
-	Loglevel  string `yaml:"loglevel"`
-	Hb        bool   `yaml:"hb,omitempty"`
-	Path      string `yaml:"path,omitempty"`
-	Grep      string `yaml:"grep,omitempty"`
-	Startmode int    `yaml:"startmode,omitempty"`
+	return targetBinList
 }
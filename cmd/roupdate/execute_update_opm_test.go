@@ -0,0 +1,67 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오후 4:10
+ */
+
+package main
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newOpmUpdate juno 바이너리가 포함된 artifact 와 명령을 기록하는 ExecuteUpdateOpm 을 만든다
+func newOpmUpdate(t *testing.T, fail string) (ExecuteUpdateOpm, *UpdateContext, *[]string) {
+	home := t.TempDir()
+	t.Setenv(EnvFatimaHome, home)
+	ctx := &UpdateContext{WorkingDir: t.TempDir(), FatimaHomeDir: home}
+	bin := filepath.Join(ctx.GetPackingDir(), "app", "juno", "juno")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(bin), 0755))
+	assert.Nil(t, os.WriteFile(bin, []byte("binary"), 0755))
+
+	commands := make([]string, 0)
+	update := ExecuteUpdateOpm{shell: func(wd, command string) error {
+		commands = append(commands, command)
+		if command == fail {
+			return errors.New("exit status 1")
+		}
+		return nil
+	}}
+	return update, ctx, &commands
+}
+
+func TestUpdateOpmStopFailed(t *testing.T) {
+	update, ctx, commands := newOpmUpdate(t, "stopro -y")
+
+	assert.NotNil(t, update.Execute(ctx))
+	assert.Equal(t, []string{"lcslack false", "stopro -y", "lcslack true"}, *commands)
+}
+
+func TestUpdateOpmStartFailed(t *testing.T) {
+	update, ctx, commands := newOpmUpdate(t, "startro -y")
+	assert.Nil(t, os.MkdirAll(filepath.Join(ctx.FatimaHomeDir, "app", "juno"), 0755))
+
+	assert.NotNil(t, update.Execute(ctx))
+	assert.Equal(t, []string{"lcslack false", "stopro -y", "startro -y", "lcslack true"}, *commands)
+
+	data, _ := os.ReadFile(filepath.Join(ctx.FatimaHomeDir, "app", "juno", "juno"))
+	assert.Equal(t, "binary", string(data))
+}
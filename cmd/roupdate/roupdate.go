@@ -40,9 +40,16 @@ command :
 optional arguments:
   -u string
         fatima packaging file url
+  -proxy string
+        proxy url for downloading. HTTPS_PROXY, HTTP_PROXY env is used if not specified
+  -insecure
+        skip tls certificate verification
+  -retry int
+        retry count when downloading fails (default 3)
 `
 
 var artifactUrl string
+var downloadOptions = DownloadOptions{Progress: os.Stdout}
 
 func main() {
 	flag.Usage = func() {
@@ -50,6 +57,9 @@ func main() {
 	}
 
 	flag.StringVar(&artifactUrl, "u", "", "fatima packaging file url")
+	flag.StringVar(&downloadOptions.Proxy, "proxy", "", "proxy url for downloading")
+	flag.BoolVar(&downloadOptions.Insecure, "insecure", false, "skip tls certificate verification")
+	flag.IntVar(&downloadOptions.Retries, "retry", 3, "retry count when downloading fails")
 
 	flag.Parse()
 	if len(flag.Args()) < 1 {
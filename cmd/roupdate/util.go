@@ -23,7 +23,7 @@ package main
 import (
 	"errors"
 	"fmt"
-	"io"
+	"github.com/fatima-go/fatima-cmd/revision"
 	"os"
 	"os/exec"
 )
@@ -74,25 +74,12 @@ func GetFilesInDir(path string) []string {
 	return targetList
 }
 
+// CopyFile dst 를 src 의 복사본으로 교체한다. dst 는 dedupe 로 다른 revision 과 hardlink 되어 있을 수 있으므로
+// 같은 디렉토리의 임시 파일에 복사한 후 rename 한다
 func CopyFile(src, dst string) error {
-	in, err := os.Open(src)
+	err := revision.CopyFile(src, dst)
 	if err != nil {
-		return fmt.Errorf("open %s error : %s", src, err.Error())
+		return fmt.Errorf("copy %s to %s error : %s", src, dst, err.Error())
 	}
-	defer in.Close()
-	out, err := os.Create(dst)
-	if err != nil {
-		return fmt.Errorf("create %s error : %s", dst, err.Error())
-	}
-	defer out.Close()
-
-	if _, err = io.Copy(out, in); err != nil {
-		return fmt.Errorf("copy error : %s", err.Error())
-	}
-	err = out.Sync()
-	if err != nil {
-		return fmt.Errorf("sync error : %s", err.Error())
-	}
-
 	return nil
 }
@@ -24,18 +24,15 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"github.com/fatima-go/fatima-cmd/config"
+	"github.com/fatima-go/fatima-cmd/localproc"
 	"github.com/fatima-go/fatima-cmd/share"
-	"io/ioutil"
-	"log"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
-var roPrograms = []string{"jupiter", "juno", "saturn"}
-
 func main() {
 	fmt.Printf("STARTING OPM PROGRAMS...\n")
 
@@ -62,76 +59,77 @@ func main() {
 		}
 	}
 
-	for _, p := range roPrograms {
-		err := startProgram(p)
-		if err != nil {
-			fmt.Printf("fail to execute %s : %s\n", p, err.Error())
-		}
-	}
-}
-
-func startProgram(procName string) error {
-	fmt.Printf("check process %s\n", procName)
-
-	pgm := buildShellPath(procName)
-	if !share.IsFileExist(pgm) {
-		pgm = buildShellPath(procName + ".sh")
-		if !share.IsFileExist(pgm) {
-			return nil
-		}
+	ports, err := localproc.ParsePorts(flags.Ports)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
 	}
 
-	workingDir := getWorkingDir(procName)
-	pid, err := execProgram(workingDir, pgm)
+	opmPrograms, err := config.LoadOpmPrograms(os.Getenv(share.EnvFatimaHome))
 	if err != nil {
-		return err
+		fmt.Printf("%s. use default opm programs\n", err.Error())
 	}
-	fmt.Printf("process %s STARTED. pid=%d\n", procName, pid)
-	time.Sleep(time.Second * 1)
-	return nil
-}
 
-func buildShellPath(procName string) string {
-	return fmt.Sprintf("%s/app/%s/%s", os.Getenv(share.EnvFatimaHome), procName, procName)
-}
-
-func getWorkingDir(procName string) string {
-	return fmt.Sprintf("%s/app/%s", os.Getenv(share.EnvFatimaHome), procName)
-}
-
-func execProgram(workingDir string, path string) (int, error) {
-	var cmd *exec.Cmd
-	//cmd = exec.Command(filepath.Base(path))
-	cmd = exec.Command("bash", "-c", filepath.Base(path))
-	cmd.Dir = workingDir
+	failed := false
+	data := make([][]string, 0)
+	for _, program := range opmPrograms {
+		p := program.Name
+		if failed {
+			data = append(data, []string{p, "", resultSkipped, ""})
+			continue
+		}
+		if !program.IsAutoStart() {
+			data = append(data, []string{p, "", resultManual, "startmode is manual"})
+			continue
+		}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		log.Fatal(err)
+		result, err := startProgram(p, ports[p], flags.Timeout)
+		message := ""
+		if err != nil {
+			failed = true
+			message = err.Error()
+		}
+		pidValue := ""
+		if result.Pid > 0 {
+			pidValue = strconv.Itoa(result.Pid)
+		}
+		data = append(data, []string{p, pidValue, result.Outcome.String(), message})
 	}
+	share.PrintTable([]string{"process", "pid", "result", "message"}, data)
 
-	err = cmd.Start()
-	if err != nil {
-		return 0, err
+	if failed {
+		os.Exit(1)
 	}
+}
 
-	slurp, _ := ioutil.ReadAll(stderr)
-	fmt.Printf("%s\n", slurp)
+const (
+	resultSkipped = "SKIPPED"
+	resultManual  = "MANUAL"
+)
 
-	return cmd.Process.Pid, nil
+// startProgram 프로그램을 기동하고 pid 파일 생성, 프로세스 생존, (지정된 경우) port open 을 확인한다
+func startProgram(procName string, port int, timeout time.Duration) (localproc.StartResult, error) {
+	fmt.Printf("check process %s\n", procName)
+	result, err := localproc.Start(procName, localproc.StartOptions{Timeout: timeout, Port: port})
+	if err == nil && result.Outcome == localproc.StartStarted {
+		fmt.Printf("process %s STARTED. pid=%d\n", procName, result.Pid)
+	}
+	return result, err
 }
 
-// cmd := exec.Command("bash", "-c", "pidof tor | xargs kill -HUP")
-
 type commandFlags struct {
-	Yes  bool
-	Args []string
+	Yes     bool
+	Timeout time.Duration
+	Ports   string
+	Args    []string
 }
 
 func buildCoommandmdFlags() commandFlags {
 	cmdFlags := commandFlags{}
 
 	flag.BoolVar(&cmdFlags.Yes, "y", false, "yes all")
+	flag.DurationVar(&cmdFlags.Timeout, "t", 30*time.Second, "timeout for each readiness check")
+	flag.StringVar(&cmdFlags.Ports, "ports", localproc.DefaultPorts, localproc.PortsUsage)
 
 	flag.Parse()
 
@@ -24,15 +24,15 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"github.com/fatima-go/fatima-cmd/config"
+	"github.com/fatima-go/fatima-cmd/localproc"
 	"github.com/fatima-go/fatima-cmd/share"
 	"os"
 	"strconv"
 	"strings"
-	"syscall"
+	"time"
 )
 
-var roPrograms = []string{"jupiter", "juno", "saturn"}
-
 func main() {
 	fmt.Printf("STOPPING OPM PROGRAMS...\n")
 
@@ -59,54 +59,52 @@ func main() {
 		}
 	}
 
-	for _, p := range roPrograms {
-		stopProgram(p)
-	}
-}
-
-func stopProgram(procName string) error {
-	fmt.Printf("check process %s\n", procName)
-
-	pid := getPidPath(procName)
-	if pid == 0 {
-		return nil
-	}
-
-	fmt.Printf("try to kill %s. pid %d\n", procName, pid)
-	return syscall.Kill(pid, syscall.SIGTERM)
-}
-
-func getPidPath(procName string) int {
-	pidFile := fmt.Sprintf("%s/app/%s/proc/%s.pid", os.Getenv(share.EnvFatimaHome), procName, procName)
-	if !share.IsFileExist(pidFile) {
-		return 0
+	opmPrograms, err := config.LoadOpmPrograms(os.Getenv(share.EnvFatimaHome))
+	if err != nil {
+		fmt.Printf("%s. use default opm programs\n", err.Error())
 	}
 
-	b, err := os.ReadFile(pidFile)
-	if err != nil {
-		fmt.Printf("fail to read pid file : %s\n", err.Error())
-		return 0
+	failed := false
+	data := make([][]string, 0)
+	// 기동 순서의 역순으로 종료한다
+	for i := len(opmPrograms) - 1; i >= 0; i-- {
+		p := opmPrograms[i].Name
+		result, err := stopProgram(p, flags.Timeout)
+		message := ""
+		if err != nil {
+			failed = true
+			message = err.Error()
+		}
+		pid := ""
+		if result.Pid > 0 {
+			pid = strconv.Itoa(result.Pid)
+		}
+		data = append(data, []string{p, pid, result.Outcome.String(), result.Elapsed.String(), message})
 	}
+	share.PrintTable([]string{"process", "pid", "result", "elapsed", "message"}, data)
 
-	content := strings.Trim(string(b), "\r\n\t ")
-	pid, err := strconv.Atoi(content)
-	if err != nil {
-		fmt.Printf("invalid pid content : [%s]\n", string(b))
-		return 0
+	if failed {
+		os.Exit(1)
 	}
+}
 
-	return pid
+// stopProgram SIGTERM 을 보내고 종료될 때까지 기다린다. timeout 이후에는 SIGKILL 을 보낸다
+func stopProgram(procName string, timeout time.Duration) (localproc.StopResult, error) {
+	fmt.Printf("stopping process %s\n", procName)
+	return localproc.Stop(procName, timeout)
 }
 
 type commandFlags struct {
-	Yes  bool
-	Args []string
+	Yes     bool
+	Timeout time.Duration
+	Args    []string
 }
 
 func buildCoommandmdFlags() commandFlags {
 	cmdFlags := commandFlags{}
 
 	flag.BoolVar(&cmdFlags.Yes, "y", false, "yes all")
+	flag.DurationVar(&cmdFlags.Timeout, "t", 10*time.Second, "timeout for graceful shutdown before SIGKILL")
 
 	flag.Parse()
 
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 11:50
+ */
+
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FatimaFolderConf        = "conf"
+	FatimaFilePackageConfig = "fatima-package.yaml"
+	OpmGroupName            = "OPM"
+)
+
+const (
+	StartModeAlways = 0 // 패키지 기동시 함께 기동한다 (default)
+	StartModeManual = 1 // 직접 기동해야 한다
+)
+
+// DefaultOpmPrograms fatima-package.yaml 을 읽을 수 없는 경우 사용하는 OPM 프로그램 목록
+var DefaultOpmPrograms = []string{"jupiter", "juno", "saturn"}
+
+type YamlFatimaPackageConfig struct {
+	Groups    []GroupItem   `yaml:"group,flow"`
+	Processes []ProcessItem `yaml:"process"`
+}
+
+type GroupItem struct {
+	Id   int    `yaml:"id"`
+	Name string `yaml:"name"`
+}
+
+type ProcessItem struct {
+	Gid       int    `yaml:"gid"`
+	Name      string `yaml:"name"`
+	Loglevel  string `yaml:"loglevel"`
+	Hb        bool   `yaml:"hb,omitempty"`
+	Path      string `yaml:"path,omitempty"`
+	Grep      string `yaml:"grep,omitempty"`
+	Startmode int    `yaml:"startmode,omitempty"`
+	Port      int    `yaml:"port,omitempty"` // http port. opm 프로그램의 상태, readiness 확인에 사용한다
+}
+
+func (p ProcessItem) IsAutoStart() bool {
+	return p.Startmode != StartModeManual
+}
+
+// LoadFatimaPackageConfig $FATIMA_HOME/conf/fatima-package.yaml 을 읽는다
+func LoadFatimaPackageConfig(fatimaHome string) (YamlFatimaPackageConfig, error) {
+	procConfig := YamlFatimaPackageConfig{}
+	procConfigYaml := filepath.Join(fatimaHome, FatimaFolderConf, FatimaFilePackageConfig)
+	data, err := os.ReadFile(procConfigYaml)
+	if err != nil {
+		return procConfig, fmt.Errorf("fail to open %s : %s", procConfigYaml, err.Error())
+	}
+
+	err = yaml.Unmarshal(data, &procConfig)
+	if err != nil {
+		return procConfig, fmt.Errorf("fail to yaml unmarshal %s : %s", procConfigYaml, err.Error())
+	}
+	return procConfig, nil
+}
+
+func (y YamlFatimaPackageConfig) GetOpmGid() int {
+	for _, item := range y.Groups {
+		if strings.ToUpper(item.Name) == OpmGroupName {
+			return item.Id
+		}
+	}
+
+	return -1 // not found
+}
+
+// GetProcessList gid 그룹에 속한 프로세스 목록을 fatima-package.yaml 에 정의된 순서대로 리턴한다
+func (y YamlFatimaPackageConfig) GetProcessList(gid int) []ProcessItem {
+	targetList := make([]ProcessItem, 0)
+	for _, item := range y.Processes {
+		if item.Gid == gid {
+			targetList = append(targetList, item)
+		}
+	}
+	return targetList
+}
+
+// LoadOpmPrograms OPM 그룹의 프로세스 목록을 읽는다.
+// fatima-package.yaml 을 읽을 수 없거나 OPM 그룹이 없는 경우 DefaultOpmPrograms 와 에러를 함께 리턴한다
+func LoadOpmPrograms(fatimaHome string) ([]ProcessItem, error) {
+	fallback := make([]ProcessItem, 0)
+	for _, name := range DefaultOpmPrograms {
+		fallback = append(fallback, ProcessItem{Name: name})
+	}
+
+	procConfig, err := LoadFatimaPackageConfig(fatimaHome)
+	if err != nil {
+		return fallback, err
+	}
+
+	opmGid := procConfig.GetOpmGid()
+	if opmGid < 0 {
+		return fallback, fmt.Errorf("not found %s group in %s", OpmGroupName, FatimaFilePackageConfig)
+	}
+
+	list := procConfig.GetProcessList(opmGid)
+	if len(list) == 0 {
+		return fallback, fmt.Errorf("there is no process in %s group", OpmGroupName)
+	}
+	return list, nil
+}
+
+// OpmProgramNames OPM 프로세스 이름 목록. 읽을 수 없는 경우 DefaultOpmPrograms 를 리턴한다
+func OpmProgramNames(fatimaHome string) []string {
+	list, _ := LoadOpmPrograms(fatimaHome)
+	names := make([]string, 0, len(list))
+	for _, p := range list {
+		names = append(names, p.Name)
+	}
+	return names
+}
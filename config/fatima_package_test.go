@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 11:50
+ */
+
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const samplePackageYaml = `group:
+  - id: 1
+    name: OPM
+  - id: 4
+    name: svc
+process:
+  - gid: 1
+    name: jupiter
+  - gid: 1
+    name: juno
+    port: 9180
+  - gid: 1
+    name: saturn
+  - gid: 1
+    name: opmhelper
+    startmode: 1
+  - gid: 4
+    name: mypgm
+`
+
+func TestLoadOpmPrograms(t *testing.T) {
+	home := t.TempDir()
+
+	list, err := LoadOpmPrograms(home)
+	assert.NotNil(t, err)
+	assert.Equal(t, DefaultOpmPrograms, OpmProgramNames(home))
+	assert.Equal(t, 3, len(list))
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(home, FatimaFolderConf), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(home, FatimaFolderConf, FatimaFilePackageConfig), []byte(samplePackageYaml), 0644))
+
+	list, err = LoadOpmPrograms(home)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"jupiter", "juno", "saturn", "opmhelper"}, OpmProgramNames(home))
+	assert.True(t, list[0].IsAutoStart())
+	assert.False(t, list[3].IsAutoStart())
+	assert.Equal(t, 9180, list[1].Port)
+	assert.Equal(t, 0, list[2].Port)
+}
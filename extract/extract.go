@@ -0,0 +1,406 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 3:40
+ */
+
+// Package extract zip/tar 아카이브를 안전하게 해제한다.
+// 경로 탈출(path traversal), symlink, 과도한 크기나 entry 수(zip bomb)를 검사하며
+// 아카이브를 해제하는 모든 명령은 이 패키지를 사용해야 한다
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type SymlinkPolicy int
+
+const (
+	// SymlinkReject symlink entry 가 있으면 해제를 중단한다
+	SymlinkReject SymlinkPolicy = iota
+	// SymlinkConfine 해제 디렉토리 내부를 가리키는 상대경로 symlink 만 허용한다
+	SymlinkConfine
+)
+
+type Options struct {
+	MaxTotalSize int64 // 해제된 파일 크기의 합. 0 이면 제한 없음
+	MaxFileSize  int64 // 파일 1개의 크기. 0 이면 제한 없음
+	MaxEntries   int   // entry 개수. 0 이면 제한 없음
+	Symlink      SymlinkPolicy
+	TrimRoot     bool // '/' 로 시작하는 entry 를 해제 디렉토리 기준 상대경로로 취급한다 (far 는 entry 이름이 '/' 로 시작한다)
+	SkipMacMeta  bool // 맥(BSD tar)에서 추가하는 AppleDouble(._*), .DS_Store, __MACOSX entry 를 해제하지 않는다
+}
+
+const (
+	defaultMaxTotalSize = 4 * 1024 * 1024 * 1024
+	defaultMaxFileSize  = 1024 * 1024 * 1024
+	defaultMaxEntries   = 100000
+)
+
+func DefaultOptions() Options {
+	return Options{
+		MaxTotalSize: defaultMaxTotalSize,
+		MaxFileSize:  defaultMaxFileSize,
+		MaxEntries:   defaultMaxEntries,
+		Symlink:      SymlinkReject,
+	}
+}
+
+var (
+	ErrInvalidPath      = errors.New("invalid file path")
+	ErrTooManyEntries   = errors.New("too many entries")
+	ErrSizeLimit        = errors.New("exceed size limit")
+	ErrSymlink          = errors.New("not permitted symlink")
+	ErrUnsupportedEntry = errors.New("unsupported entry type")
+	ErrTruncated        = errors.New("archive is truncated")
+	ErrCorrupted        = errors.New("archive is corrupted")
+)
+
+// extractor 해제 디렉토리와 지금까지 해제된 entry 수, 크기를 관리한다
+type extractor struct {
+	destDir string // symlink 가 해석된 절대경로
+	opt     Options
+	entries int
+	written int64
+	links   []string // 생성한 symlink 목록
+	dirs    []dirMode
+}
+
+// dirMode 쓰기 권한이 없는 디렉토리도 하위 entry 를 해제할 수 있도록 디렉토리 mode 는 마지막에 적용한다
+type dirMode struct {
+	path string
+	mode os.FileMode
+}
+
+func newExtractor(destDir string, opt Options) (*extractor, error) {
+	err := os.MkdirAll(destDir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create %s : %s", destDir, err.Error())
+	}
+
+	abs, err := filepath.Abs(destDir)
+	if err != nil {
+		return nil, err
+	}
+
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &extractor{destDir: real, opt: opt}, nil
+}
+
+// count entry 개수 제한을 검사한다
+func (e *extractor) count(name string) error {
+	e.entries++
+	if e.opt.MaxEntries > 0 && e.entries > e.opt.MaxEntries {
+		return fmt.Errorf("%s : %w (max %d)", name, ErrTooManyEntries, e.opt.MaxEntries)
+	}
+	return nil
+}
+
+// resolve 아카이브 내 entry 이름을 해제 디렉토리 하위 경로로 변환한다.
+// 해제 디렉토리 자체를 가리키는 entry 의 경우 빈 문자열을 리턴한다
+func (e *extractor) resolve(name string) (string, error) {
+	n := strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(n, "/") {
+		if !e.opt.TrimRoot {
+			return "", fmt.Errorf("%s : %w", name, ErrInvalidPath)
+		}
+		n = strings.TrimLeft(n, "/")
+	}
+
+	clean := path.Clean(n)
+	if len(n) == 0 || clean == "." {
+		return "", nil
+	}
+
+	if clean == ".." || strings.HasPrefix(clean, "../") || filepath.IsAbs(clean) {
+		return "", fmt.Errorf("%s : %w", name, ErrInvalidPath)
+	}
+
+	return filepath.Join(e.destDir, filepath.FromSlash(clean)), nil
+}
+
+// isMacMeta 맥에서 만든 아카이브에 추가되는 메타데이터 entry 인지 확인한다
+func isMacMeta(name string) bool {
+	n := strings.Trim(strings.ReplaceAll(name, "\\", "/"), "/")
+	for _, c := range strings.Split(n, "/") {
+		if c == "__MACOSX" {
+			return true
+		}
+	}
+	base := path.Base(n)
+	return strings.HasPrefix(base, "._") || base == ".DS_Store"
+}
+
+// skip 해제하지 않을 entry 인지 확인한다
+func (e *extractor) skip(name string) bool {
+	return e.opt.SkipMacMeta && isMacMeta(name)
+}
+
+func (e *extractor) within(p string) bool {
+	return p == e.destDir || strings.HasPrefix(p, e.destDir+string(os.PathSeparator))
+}
+
+// prepareParent 상위 디렉토리를 생성하고 (symlink 를 따라간) 실제 경로가 해제 디렉토리 내부인지 검사한다
+func (e *extractor) prepareParent(target string) (string, error) {
+	parent := filepath.Dir(target)
+	err := os.MkdirAll(parent, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	real, err := filepath.EvalSymlinks(parent)
+	if err != nil {
+		return "", err
+	}
+
+	if !e.within(real) {
+		return "", fmt.Errorf("%s : %w", target, ErrInvalidPath)
+	}
+	return real, nil
+}
+
+// removeLink 이미 존재하는 symlink 를 따라가서 덮어쓰지 않도록 제거한다
+func removeLink(target string) error {
+	info, err := os.Lstat(target)
+	if err != nil {
+		return nil
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return os.Remove(target)
+	}
+	return nil
+}
+
+func (e *extractor) mkdir(target string, mode os.FileMode) error {
+	if len(target) == 0 {
+		return nil
+	}
+
+	_, err := e.prepareParent(target)
+	if err != nil {
+		return err
+	}
+
+	err = removeLink(target)
+	if err != nil {
+		return err
+	}
+
+	perm := mode.Perm()
+	if perm == 0 {
+		perm = 0755
+	}
+
+	err = os.MkdirAll(target, perm|0700)
+	if err != nil {
+		return err
+	}
+
+	e.dirs = append(e.dirs, dirMode{path: target, mode: perm})
+	return nil
+}
+
+func (e *extractor) limit() int64 {
+	limit := int64(math.MaxInt64)
+	if e.opt.MaxFileSize > 0 {
+		limit = e.opt.MaxFileSize
+	}
+	if e.opt.MaxTotalSize > 0 && e.opt.MaxTotalSize-e.written < limit {
+		limit = e.opt.MaxTotalSize - e.written
+	}
+	return limit
+}
+
+func (e *extractor) writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if len(target) == 0 {
+		return fmt.Errorf("%w : empty file name", ErrInvalidPath)
+	}
+
+	_, err := e.prepareParent(target)
+	if err != nil {
+		return err
+	}
+
+	err = removeLink(target)
+	if err != nil {
+		return err
+	}
+
+	perm := mode.Perm()
+	if perm == 0 {
+		perm = 0644
+	}
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("fail to open %s : %s", target, err.Error())
+	}
+	defer out.Close()
+
+	limit := e.limit()
+	if limit < math.MaxInt64 {
+		r = io.LimitReader(r, limit+1)
+	}
+
+	n, err := io.Copy(out, r)
+	e.written += n
+	if err != nil {
+		return fmt.Errorf("fail to copy %s : %w", target, err)
+	}
+	if n > limit {
+		return fmt.Errorf("%s : %w", target, ErrSizeLimit)
+	}
+
+	// OpenFile 의 perm 은 umask 의 영향을 받고 기존 파일에는 적용되지 않는다
+	err = out.Chmod(perm)
+	if err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+func (e *extractor) symlink(target, linkname string) error {
+	if len(target) == 0 || e.opt.Symlink != SymlinkConfine {
+		return fmt.Errorf("%s -> %s : %w", target, linkname, ErrSymlink)
+	}
+
+	if len(linkname) == 0 || filepath.IsAbs(linkname) || strings.HasPrefix(linkname, "/") {
+		return fmt.Errorf("%s -> %s : %w", target, linkname, ErrSymlink)
+	}
+
+	parent, err := e.prepareParent(target)
+	if err != nil {
+		return err
+	}
+
+	if !e.confined(parent, linkname) {
+		return fmt.Errorf("%s -> %s : %w", target, linkname, ErrSymlink)
+	}
+
+	err = removeLink(target)
+	if err != nil {
+		return err
+	}
+
+	err = os.Symlink(linkname, target)
+	if err != nil {
+		return err
+	}
+
+	e.links = append(e.links, target)
+	return nil
+}
+
+// confined linkname 을 parent 에서부터 한 단계씩 (이미 존재하는 symlink 는 따라가며) 해석해서
+// 모든 단계가 해제 디렉토리 내부인지 검사한다. 단순히 문자열로 Join 할 경우
+// 위쪽을 가리키는 symlink 와 ".." 를 조합해서 해제 디렉토리를 벗어날 수 있기 때문이다
+func (e *extractor) confined(parent, linkname string) bool {
+	cur := parent
+	for _, c := range strings.Split(filepath.ToSlash(linkname), "/") {
+		switch c {
+		case "", ".":
+			continue
+		case "..":
+			cur = filepath.Dir(cur)
+		default:
+			cur = filepath.Join(cur, c)
+			if info, err := os.Lstat(cur); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				real, err := filepath.EvalSymlinks(cur)
+				if err != nil {
+					return false
+				}
+				cur = real
+			}
+		}
+
+		if !e.within(cur) {
+			return false
+		}
+	}
+	return true
+}
+
+// finish 해제가 끝난 후 symlink 를 검사하고 디렉토리 mode 를 적용한다
+func (e *extractor) finish() error {
+	err := e.verifyLinks()
+	if err != nil {
+		return err
+	}
+
+	// 하위 디렉토리부터 적용한다
+	for i := len(e.dirs) - 1; i >= 0; i-- {
+		err = os.Chmod(e.dirs[i].path, e.dirs[i].mode)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyLinks 해제가 끝난 후 생성한 모든 symlink 가 실제로 해제 디렉토리 내부를 가리키는지 검사한다.
+// 해제 도중 나중에 생성된 symlink 에 의해 앞서 만든 symlink 의 대상이 바뀔 수 있기 때문이다
+func (e *extractor) verifyLinks() error {
+	for _, link := range e.links {
+		real, err := filepath.EvalSymlinks(link)
+		if err != nil {
+			continue
+		}
+		if !e.within(real) {
+			_ = os.Remove(link)
+			return fmt.Errorf("%s : %w", link, ErrSymlink)
+		}
+	}
+	return nil
+}
+
+func (e *extractor) hardlink(target, linkname string) error {
+	src, err := e.resolve(linkname)
+	if err != nil {
+		return err
+	}
+	if len(src) == 0 || len(target) == 0 {
+		return fmt.Errorf("%s => %s : %w", target, linkname, ErrInvalidPath)
+	}
+
+	real, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return fmt.Errorf("fail to find link source %s : %s", linkname, err.Error())
+	}
+	if !e.within(real) {
+		return fmt.Errorf("%s => %s : %w", target, linkname, ErrInvalidPath)
+	}
+
+	_, err = e.prepareParent(target)
+	if err != nil {
+		return err
+	}
+
+	_ = os.Remove(target)
+	return os.Link(real, target)
+}
@@ -0,0 +1,268 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 3:40
+ */
+
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type entry struct {
+	name     string
+	mode     os.FileMode
+	body     string
+	linkname string // tar hardlink
+}
+
+func buildZip(entries ...entry) []byte {
+	var buff bytes.Buffer
+	zw := zip.NewWriter(&buff)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		header.SetMode(e.mode)
+		w, _ := zw.CreateHeader(header)
+		_, _ = w.Write([]byte(e.body))
+	}
+	_ = zw.Close()
+	return buff.Bytes()
+}
+
+func buildTar(entries ...entry) []byte {
+	var buff bytes.Buffer
+	tw := tar.NewWriter(&buff)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: int64(e.mode.Perm()), Size: int64(len(e.body))}
+		switch {
+		case e.mode.IsDir():
+			header.Typeflag = tar.TypeDir
+			header.Size = 0
+		case e.mode&os.ModeSymlink != 0:
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = e.body
+			header.Size = 0
+		case len(e.linkname) > 0:
+			header.Typeflag = tar.TypeLink
+			header.Linkname = e.linkname
+			header.Size = 0
+		default:
+			header.Typeflag = tar.TypeReg
+		}
+		_ = tw.WriteHeader(header)
+		if header.Typeflag == tar.TypeReg {
+			_, _ = tw.Write([]byte(e.body))
+		}
+	}
+	_ = tw.Close()
+	return buff.Bytes()
+}
+
+func extractZipBytes(t *testing.T, data []byte, opt Options) (string, error) {
+	sandbox := t.TempDir()
+	zipfile := filepath.Join(sandbox, "sample.zip")
+	_ = os.WriteFile(zipfile, data, 0644)
+	dest := filepath.Join(sandbox, "dest")
+	return dest, Zip(zipfile, dest, opt)
+}
+
+func TestZipTraversal(t *testing.T) {
+	dest, err := extractZipBytes(t, buildZip(entry{name: "../evil", mode: 0644, body: "x"}), DefaultOptions())
+	assert.ErrorIs(t, err, ErrInvalidPath)
+	assert.False(t, fileExist(filepath.Join(filepath.Dir(dest), "evil")))
+
+	_, err = extractZipBytes(t, buildZip(entry{name: "a/../../evil", mode: 0644, body: "x"}), DefaultOptions())
+	assert.ErrorIs(t, err, ErrInvalidPath)
+
+	_, err = extractZipBytes(t, buildZip(entry{name: "/etc/evil", mode: 0644, body: "x"}), DefaultOptions())
+	assert.ErrorIs(t, err, ErrInvalidPath)
+
+	// far 는 '/' 로 시작하는 entry 이름을 사용한다
+	opt := DefaultOptions()
+	opt.TrimRoot = true
+	dest, err = extractZipBytes(t, buildZip(
+		entry{name: "/", mode: os.ModeDir | 0755},
+		entry{name: "/bin/", mode: os.ModeDir | 0755},
+		entry{name: "/bin/run.sh", mode: 0755, body: "#!/bin/sh\n"},
+	), opt)
+	assert.Nil(t, err)
+	info, err := os.Stat(filepath.Join(dest, "bin", "run.sh"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
+
+func TestZipSymlink(t *testing.T) {
+	link := entry{name: "current", mode: os.ModeSymlink | 0777, body: "release"}
+	_, err := extractZipBytes(t, buildZip(link), DefaultOptions())
+	assert.ErrorIs(t, err, ErrSymlink)
+
+	opt := DefaultOptions()
+	opt.Symlink = SymlinkConfine
+	dest, err := extractZipBytes(t, buildZip(entry{name: "release/", mode: os.ModeDir | 0755}, link), opt)
+	assert.Nil(t, err)
+	target, _ := os.Readlink(filepath.Join(dest, "current"))
+	assert.Equal(t, "release", target)
+
+	_, err = extractZipBytes(t, buildZip(entry{name: "evil", mode: os.ModeSymlink | 0777, body: "../../etc"}), opt)
+	assert.ErrorIs(t, err, ErrSymlink)
+
+	_, err = extractZipBytes(t, buildZip(entry{name: "evil", mode: os.ModeSymlink | 0777, body: "/etc"}), opt)
+	assert.ErrorIs(t, err, ErrSymlink)
+
+	// 해제 디렉토리 자체를 가리키는 symlink 와 ".." 를 조합한 탈출
+	_, err = extractZipBytes(t, buildZip(
+		entry{name: "self", mode: os.ModeSymlink | 0777, body: "."},
+		entry{name: "evil", mode: os.ModeSymlink | 0777, body: "self/.."},
+	), opt)
+	assert.ErrorIs(t, err, ErrSymlink)
+
+	// symlink 를 통해 해제 디렉토리 밖에 파일을 쓰려는 경우. entry 이름은 symlink 를 따라가지 않고 정리된다
+	dest, err = extractZipBytes(t, buildZip(
+		entry{name: "up", mode: os.ModeSymlink | 0777, body: "."},
+		entry{name: "up/../evil", mode: 0644, body: "x"},
+	), opt)
+	assert.Nil(t, err)
+	assert.True(t, fileExist(filepath.Join(dest, "evil")))
+	assert.False(t, fileExist(filepath.Join(filepath.Dir(dest), "evil")))
+}
+
+func TestZipBomb(t *testing.T) {
+	opt := DefaultOptions()
+	opt.MaxFileSize = 1024
+	_, err := extractZipBytes(t, buildZip(entry{name: "bomb", mode: 0644, body: string(make([]byte, 1024*1024))}), opt)
+	assert.ErrorIs(t, err, ErrSizeLimit)
+
+	opt = DefaultOptions()
+	opt.MaxTotalSize = 1500
+	_, err = extractZipBytes(t, buildZip(
+		entry{name: "a", mode: 0644, body: string(make([]byte, 1000))},
+		entry{name: "b", mode: 0644, body: string(make([]byte, 1000))},
+	), opt)
+	assert.ErrorIs(t, err, ErrSizeLimit)
+
+	opt = DefaultOptions()
+	opt.MaxEntries = 2
+	_, err = extractZipBytes(t, buildZip(
+		entry{name: "a", mode: 0644},
+		entry{name: "b", mode: 0644},
+		entry{name: "c", mode: 0644},
+	), opt)
+	assert.ErrorIs(t, err, ErrTooManyEntries)
+}
+
+func TestTar(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "dest")
+	err := Tar(bytes.NewReader(buildTar(
+		entry{name: "pkg/", mode: os.ModeDir | 0755},
+		entry{name: "pkg/bin/rodis", mode: 0755, body: "binary"},
+		entry{name: "pkg/bin/rodis2", linkname: "pkg/bin/rodis"},
+	)), dest, DefaultOptions())
+	assert.Nil(t, err)
+	info, err := os.Stat(filepath.Join(dest, "pkg", "bin", "rodis2"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	err = Tar(bytes.NewReader(buildTar(entry{name: "../evil", mode: 0644, body: "x"})), dest, DefaultOptions())
+	assert.ErrorIs(t, err, ErrInvalidPath)
+
+	err = Tar(bytes.NewReader(buildTar(entry{name: "/evil", mode: 0644, body: "x"})), dest, DefaultOptions())
+	assert.ErrorIs(t, err, ErrInvalidPath)
+
+	err = Tar(bytes.NewReader(buildTar(entry{name: "evil", linkname: "../../etc/passwd"})), dest, DefaultOptions())
+	assert.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func gzipBytes(b []byte) []byte {
+	var buff bytes.Buffer
+	gw := gzip.NewWriter(&buff)
+	_, _ = gw.Write(b)
+	_ = gw.Close()
+	return buff.Bytes()
+}
+
+func TestTarGzSkipMacMetaAndModes(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "package.tar.gz")
+	_ = os.WriteFile(src, gzipBytes(buildTar(
+		entry{name: "pkg/", mode: os.ModeDir | 0750},
+		entry{name: "pkg/._rodis", mode: 0644, body: "apple double"},
+		entry{name: "pkg/.DS_Store", mode: 0644, body: "finder"},
+		entry{name: "__MACOSX/pkg/._rodis", mode: 0644, body: "apple double"},
+		entry{name: "pkg/bin/", mode: os.ModeDir | 0555},
+		entry{name: "pkg/bin/rodis", mode: 0750, body: "binary"},
+		entry{name: "pkg/.env", mode: 0600, body: "A=1"},
+	)), 0644)
+
+	dest := filepath.Join(dir, "dest")
+	opt := DefaultOptions()
+	opt.SkipMacMeta = true
+	err := TarGz(src, dest, opt)
+	assert.Nil(t, err)
+
+	assert.False(t, fileExist(filepath.Join(dest, "pkg", "._rodis")))
+	assert.False(t, fileExist(filepath.Join(dest, "pkg", ".DS_Store")))
+	assert.False(t, fileExist(filepath.Join(dest, "__MACOSX")))
+	assert.True(t, fileExist(filepath.Join(dest, "pkg", ".env")))
+
+	info, _ := os.Stat(filepath.Join(dest, "pkg"))
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+	info, _ = os.Stat(filepath.Join(dest, "pkg", "bin"))
+	assert.Equal(t, os.FileMode(0555), info.Mode().Perm())
+	info, _ = os.Stat(filepath.Join(dest, "pkg", "bin", "rodis"))
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+
+	_ = os.Chmod(filepath.Join(dest, "pkg", "bin"), 0755)
+}
+
+func TestTarGzTruncated(t *testing.T) {
+	dir := t.TempDir()
+	archive := gzipBytes(buildTar(
+		entry{name: "pkg/bin/rodis", mode: 0755, body: string(bytes.Repeat([]byte("rodis"), 10000))},
+		entry{name: "pkg/bin/rolog", mode: 0755, body: "binary"},
+	))
+
+	// 압축 데이터 중간에서 잘린 경우
+	src := filepath.Join(dir, "half.tar.gz")
+	_ = os.WriteFile(src, archive[:len(archive)/2], 0644)
+	err := TarGz(src, filepath.Join(dir, "half"), DefaultOptions())
+	assert.ErrorIs(t, err, ErrTruncated)
+
+	// gzip trailer 만 잘린 경우
+	src = filepath.Join(dir, "trailer.tar.gz")
+	_ = os.WriteFile(src, archive[:len(archive)-4], 0644)
+	err = TarGz(src, filepath.Join(dir, "trailer"), DefaultOptions())
+	assert.ErrorIs(t, err, ErrTruncated)
+
+	// 파일 본문 중간에서 잘린 tar
+	tarball := buildTar(entry{name: "pkg/bin/rodis", mode: 0755, body: string(bytes.Repeat([]byte("rodis"), 1000))})
+	err = Tar(bytes.NewReader(tarball[:2048]), filepath.Join(dir, "body"), DefaultOptions())
+	assert.ErrorIs(t, err, ErrTruncated)
+	assert.Contains(t, err.Error(), "pkg/bin/rodis")
+}
+
+func fileExist(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 3:40
+ */
+
+package extract
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// maliciousEntries fuzzing 의 seed 로 사용할 악의적인 아카이브 구성
+var maliciousEntries = [][]entry{
+	{{name: "../evil", mode: 0644, body: "x"}},
+	{{name: "/etc/evil", mode: 0644, body: "x"}},
+	{{name: "a/../../evil", mode: 0644, body: "x"}},
+	{{name: "..\\evil", mode: 0644, body: "x"}},
+	{{name: "evil", mode: os.ModeSymlink | 0777, body: "../../etc"}},
+	{{name: "evil", mode: os.ModeSymlink | 0777, body: "/etc"}},
+	{
+		{name: "self", mode: os.ModeSymlink | 0777, body: "."},
+		{name: "evil", mode: os.ModeSymlink | 0777, body: "self/.."},
+	},
+	{
+		{name: "up", mode: os.ModeSymlink | 0777, body: "."},
+		{name: "up/../evil", mode: 0644, body: "x"},
+	},
+	{
+		{name: "d/", mode: os.ModeDir | 0755},
+		{name: "d/up", mode: os.ModeSymlink | 0777, body: ".."},
+		{name: "x", mode: os.ModeSymlink | 0777, body: "d/up/.."},
+	},
+	{{name: "evil", linkname: "../../etc/passwd"}},
+	{{name: "bomb", mode: 0644, body: strings.Repeat("0", 64*1024)}},
+}
+
+// fuzzOptions 작은 제한으로 zip bomb 검사도 함께 동작하도록 한다
+func fuzzOptions() Options {
+	return Options{
+		MaxTotalSize: 32 * 1024,
+		MaxFileSize:  16 * 1024,
+		MaxEntries:   64,
+		Symlink:      SymlinkConfine,
+	}
+}
+
+// assertConfined 해제 디렉토리 밖에 아무것도 생성되지 않았고 모든 symlink 가 내부를 가리키는지 검사한다
+func assertConfined(t *testing.T, sandbox, dest string, archiveName string) {
+	entries, err := os.ReadDir(sandbox)
+	if err != nil {
+		t.Fatalf("fail to read sandbox : %s", err.Error())
+	}
+	for _, e := range entries {
+		if e.Name() != "dest" && e.Name() != archiveName {
+			t.Fatalf("file created outside of dest : %s", e.Name())
+		}
+	}
+
+	realDest, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return
+	}
+
+	_ = filepath.Walk(dest, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+		real, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil
+		}
+		if real != realDest && !strings.HasPrefix(real, realDest+string(os.PathSeparator)) {
+			t.Fatalf("symlink %s escapes dest : %s", path, real)
+		}
+		return nil
+	})
+}
+
+func FuzzZip(f *testing.F) {
+	for _, entries := range maliciousEntries {
+		f.Add(buildZip(entries...))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		sandbox := t.TempDir()
+		zipfile := filepath.Join(sandbox, "fuzz.zip")
+		err := os.WriteFile(zipfile, data, 0644)
+		if err != nil {
+			t.Fatalf("fail to write zip : %s", err.Error())
+		}
+
+		dest := filepath.Join(sandbox, "dest")
+		_ = Zip(zipfile, dest, fuzzOptions())
+		assertConfined(t, sandbox, dest, "fuzz.zip")
+	})
+}
+
+func FuzzTar(f *testing.F) {
+	for _, entries := range maliciousEntries {
+		f.Add(buildTar(entries...))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		sandbox := t.TempDir()
+		dest := filepath.Join(sandbox, "dest")
+		_ = Tar(bytes.NewReader(data), dest, fuzzOptions())
+		assertConfined(t, sandbox, dest, "")
+	})
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 3:40
+ */
+
+package extract
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// TarGz tar.gz 파일을 destDir 에 해제한다
+func TarGz(src, destDir string, opt Options) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("fail to open %s : %s", src, err.Error())
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("fail to open gzip reader %s : %s", src, err.Error())
+	}
+	defer gz.Close()
+
+	err = Tar(gz, destDir, opt)
+	if err != nil {
+		return fmt.Errorf("%s : %w", src, err)
+	}
+
+	// tar 는 end of archive 에서 읽기를 멈추므로 gzip 의 나머지를 읽어서 trailer(crc, 크기)까지 검사한다
+	_, err = io.Copy(io.Discard, gz)
+	if err != nil {
+		return fmt.Errorf("%s : %w", src, streamError(err))
+	}
+	return nil
+}
+
+// streamError 압축 스트림을 읽다 발생한 에러를 ErrTruncated, ErrCorrupted 로 구분한다
+func streamError(err error) error {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrTruncated
+	}
+	if errors.Is(err, gzip.ErrChecksum) || errors.Is(err, gzip.ErrHeader) || errors.Is(err, tar.ErrHeader) {
+		return fmt.Errorf("%w : %s", ErrCorrupted, err.Error())
+	}
+	return err
+}
+
+// Tar tar 스트림을 destDir 에 해제한다
+func Tar(r io.Reader, destDir string, opt Options) error {
+	e, err := newExtractor(destDir, opt)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	last := ""
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return e.finish()
+		}
+		if err != nil {
+			if len(last) == 0 {
+				return fmt.Errorf("fail to read tar : %w", streamError(err))
+			}
+			return fmt.Errorf("fail to read tar after %s : %w", last, streamError(err))
+		}
+
+		err = e.count(header.Name)
+		if err != nil {
+			return err
+		}
+		last = header.Name
+
+		if e.skip(header.Name) {
+			continue
+		}
+
+		err = extractTarEntry(e, tr, header)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%s (%d bytes) : %w", header.Name, header.Size, ErrTruncated)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractTarEntry(e *extractor, tr *tar.Reader, header *tar.Header) error {
+	if header.Typeflag == tar.TypeXGlobalHeader {
+		return nil
+	}
+
+	target, err := e.resolve(header.Name)
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(header.Mode).Perm()
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return e.mkdir(target, mode)
+	case tar.TypeReg:
+		if header.Size > e.limit() {
+			return fmt.Errorf("%s : %w", header.Name, ErrSizeLimit)
+		}
+		return e.writeFile(target, tr, mode)
+	case tar.TypeSymlink:
+		return e.symlink(target, header.Linkname)
+	case tar.TypeLink:
+		return e.hardlink(target, header.Linkname)
+	}
+
+	return fmt.Errorf("%s (type %c) : %w", header.Name, header.Typeflag, ErrUnsupportedEntry)
+}
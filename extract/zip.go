@@ -0,0 +1,119 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 3:40
+ */
+
+package extract
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	maxSymlinkTargetSize = 4096
+)
+
+// Zip zip(far) 파일을 destDir 에 해제한다
+func Zip(src, destDir string, opt Options) error {
+	archive, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("fail to open zip reader %s : %s", src, err.Error())
+	}
+	defer archive.Close()
+
+	return extractZip(&archive.Reader, destDir, opt)
+}
+
+func extractZip(archive *zip.Reader, destDir string, opt Options) error {
+	e, err := newExtractor(destDir, opt)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range archive.File {
+		err = e.count(f.Name)
+		if err != nil {
+			return err
+		}
+
+		if e.skip(f.Name) {
+			continue
+		}
+
+		err = extractZipEntry(e, f)
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.finish()
+}
+
+func extractZipEntry(e *extractor, f *zip.File) error {
+	target, err := e.resolve(f.Name)
+	if err != nil {
+		return err
+	}
+
+	mode := f.Mode()
+	switch {
+	case mode.IsDir():
+		return e.mkdir(target, mode)
+	case mode&os.ModeSymlink != 0:
+		linkname, err := readZipEntry(f, maxSymlinkTargetSize)
+		if err != nil {
+			return err
+		}
+		return e.symlink(target, string(linkname))
+	case !mode.IsRegular():
+		return fmt.Errorf("%s (%s) : %w", f.Name, mode.Type(), ErrUnsupportedEntry)
+	}
+
+	// 압축 해제 전에 header 에 기록된 크기로 먼저 검사한다. 실제 크기는 writeFile 에서 다시 검사한다
+	if f.UncompressedSize64 > uint64(e.limit()) {
+		return fmt.Errorf("%s : %w", f.Name, ErrSizeLimit)
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("fail to open %s file : %s", f.Name, err.Error())
+	}
+	defer r.Close()
+
+	return e.writeFile(target, r, mode)
+}
+
+func readZipEntry(f *zip.File, limit int64) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("fail to open %s file : %s", f.Name, err.Error())
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("fail to read %s file : %s", f.Name, err.Error())
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s : %w", f.Name, ErrSizeLimit)
+	}
+	return data, nil
+}
@@ -0,0 +1,356 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 2:10
+ */
+
+package far
+
+import (
+	"archive/zip"
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"encoding/binary"
+	"fmt"
+	. "github.com/fatima-go/fatima-cmd/domain"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	PlatformDirName = "platform"
+
+	FormatElf     = "elf"
+	FormatMacho   = "macho"
+	FormatUnknown = "-"
+)
+
+// BinaryPlatform far 의 platform/<os>_<arch>/ 디렉토리에 들어있는 파일의 헤더 분석 결과
+type BinaryPlatform struct {
+	Name   string   // zip entry name
+	Dir    string   // platform 디렉토리 이름. e.g) linux_amd64
+	Format string   // elf, macho 혹은 바이너리가 아닌 경우 FormatUnknown
+	OS     string   // 헤더에서 읽어낸 os
+	Archs  []string // 헤더에서 읽어낸 architecture. fat(universal) mach-o 의 경우 여러개일 수 있다
+}
+
+// IsBinary 실행 바이너리(elf/mach-o) 여부. 스크립트 등은 검증 대상이 아니다
+func (b BinaryPlatform) IsBinary() bool {
+	return b.Format != FormatUnknown
+}
+
+func (b BinaryPlatform) Platform() string {
+	if !b.IsBinary() {
+		return FormatUnknown
+	}
+	return fmt.Sprintf("%s_%s", b.OS, strings.Join(b.Archs, ","))
+}
+
+// Supports 바이너리가 주어진 os, arch 에서 실행 가능한지 여부
+func (b BinaryPlatform) Supports(os, arch string) bool {
+	if !b.IsBinary() {
+		return true
+	}
+	if b.OS != os {
+		return false
+	}
+	for _, a := range b.Archs {
+		if a == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesDir 바이너리 헤더가 자신이 들어있는 platform 디렉토리 이름과 일치하는지 여부
+func (b BinaryPlatform) MatchesDir() bool {
+	os, arch, ok := strings.Cut(b.Dir, "_")
+	if !ok {
+		return false
+	}
+	return b.Supports(os, arch)
+}
+
+// InspectPlatforms far 파일의 platform 디렉토리 하위 모든 파일의 헤더를 분석한다
+func InspectPlatforms(farFile string) ([]BinaryPlatform, error) {
+	archive, err := zip.OpenReader(farFile)
+	if err != nil {
+		return nil, fmt.Errorf("fail to open zip reader %s : %s", farFile, err.Error())
+	}
+	defer archive.Close()
+
+	list := make([]BinaryPlatform, 0)
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		dir, ok := platformDirOf(f.Name)
+		if !ok {
+			continue
+		}
+
+		b, err := inspectEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		b.Dir = dir
+		list = append(list, b)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// VerifyPlatform far 내의 모든 바이너리가 자신의 platform 디렉토리 이름과 일치하는지,
+// 그리고 target 플랫폼 디렉토리의 바이너리가 target 호스트의 os/arch 와 일치하는지 검사한다
+func VerifyPlatform(farFile string, target PlatformResp) error {
+	list, err := InspectPlatforms(farFile)
+	if err != nil {
+		return err
+	}
+
+	if len(target.OS) > 0 && !hasPlatformDir(list, target.String()) {
+		return fmt.Errorf("far doesn't support platform %s", target)
+	}
+
+	mismatch := make([]string, 0)
+	for _, b := range list {
+		if !b.MatchesDir() {
+			mismatch = append(mismatch, fmt.Sprintf("%s is %s binary", b.Name, b.Platform()))
+			continue
+		}
+		if b.Dir == target.String() && !b.Supports(target.OS, target.Architecture) {
+			mismatch = append(mismatch, fmt.Sprintf("%s is %s binary but target is %s", b.Name, b.Platform(), target))
+		}
+	}
+
+	if len(mismatch) > 0 {
+		return fmt.Errorf("platform mismatch\n%s", strings.Join(mismatch, "\n"))
+	}
+	return nil
+}
+
+func hasPlatformDir(list []BinaryPlatform, platform string) bool {
+	for _, b := range list {
+		if b.Dir == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// platformDirOf zip entry 이름이 platform/<name>/ 하위일 경우 <name> 을 리턴한다
+func platformDirOf(name string) (string, bool) {
+	name = strings.TrimPrefix(name, "/")
+	rest, ok := strings.CutPrefix(name, PlatformDirName+"/")
+	if !ok {
+		return "", false
+	}
+	dir, _, ok := strings.Cut(rest, "/")
+	if !ok || len(dir) == 0 {
+		return "", false
+	}
+	return dir, true
+}
+
+var (
+	elfMagic       = []byte("\x7fELF")
+	machoMagicList = []uint32{macho.Magic32, macho.Magic64}
+)
+
+const (
+	// maxHeaderSize 플랫폼 확인을 위해 읽는 파일 앞부분의 크기. 바이너리 전체를 메모리에 읽지 않는다
+	maxHeaderSize = 4096
+	// maxFatArch java class 파일도 fat mach-o 와 같은 magic 을 사용한다. class 파일의 major version(45 이상)과 구분한다
+	maxFatArch = 45
+
+	elfHeaderSize   = 20 // e_ident(16) + e_type(2) + e_machine(2)
+	machoHeaderSize = 8  // magic(4) + cputype(4)
+	fatHeaderSize   = 8  // magic(4) + nfat_arch(4)
+	fatArchSize     = 20 // cputype, cpusubtype, offset, size, align
+)
+
+func inspectEntry(f *zip.File) (BinaryPlatform, error) {
+	b := BinaryPlatform{Name: f.Name, Format: FormatUnknown}
+
+	r, err := f.Open()
+	if err != nil {
+		return b, fmt.Errorf("fail to open %s file : %s", f.Name, err.Error())
+	}
+	defer r.Close()
+
+	data := make([]byte, maxHeaderSize)
+	n, err := io.ReadFull(r, data)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return b, fmt.Errorf("fail to read %s file : %s", f.Name, err.Error())
+	}
+	data = data[:n]
+
+	if len(data) < 4 {
+		return b, nil
+	}
+
+	switch {
+	case bytes.Equal(data[:4], elfMagic):
+		return inspectElf(b, data)
+	case isMachoMagic(data[:4]):
+		return inspectMacho(b, data)
+	case binary.BigEndian.Uint32(data[:4]) == macho.MagicFat:
+		return inspectMachoFat(b, data)
+	}
+
+	return b, nil
+}
+
+func isMachoMagic(head []byte) bool {
+	return isMachoMagicOf(binary.BigEndian.Uint32(head)) || isMachoMagicOf(binary.LittleEndian.Uint32(head))
+}
+
+// inspectElf e_ident 와 e_machine 으로 os, arch 를 확인한다
+func inspectElf(b BinaryPlatform, data []byte) (BinaryPlatform, error) {
+	if len(data) < elfHeaderSize {
+		return b, fmt.Errorf("invalid elf header %s : too short", b.Name)
+	}
+
+	var order binary.ByteOrder
+	switch elf.Data(data[elf.EI_DATA]) {
+	case elf.ELFDATA2LSB:
+		order = binary.LittleEndian
+	case elf.ELFDATA2MSB:
+		order = binary.BigEndian
+	default:
+		return b, fmt.Errorf("invalid elf header %s : unknown data encoding %d", b.Name, data[elf.EI_DATA])
+	}
+
+	b.Format = FormatElf
+	b.OS = elfOS(elf.OSABI(data[elf.EI_OSABI]))
+	b.Archs = []string{elfArch(elf.Machine(order.Uint16(data[18:20])), order)}
+	return b, nil
+}
+
+// inspectMacho mach header 의 cputype 으로 arch 를 확인한다
+func inspectMacho(b BinaryPlatform, data []byte) (BinaryPlatform, error) {
+	if len(data) < machoHeaderSize {
+		return b, fmt.Errorf("invalid mach-o header %s : too short", b.Name)
+	}
+
+	var order binary.ByteOrder = binary.BigEndian
+	if !isMachoMagicOf(binary.BigEndian.Uint32(data[:4])) {
+		order = binary.LittleEndian
+	}
+
+	b.Format = FormatMacho
+	b.OS = "darwin"
+	b.Archs = []string{machoArch(macho.Cpu(order.Uint32(data[4:8])))}
+	return b, nil
+}
+
+func isMachoMagicOf(magic uint32) bool {
+	for _, m := range machoMagicList {
+		if magic == m {
+			return true
+		}
+	}
+	return false
+}
+
+// inspectMachoFat fat header 의 fat_arch 목록으로 arch 를 확인한다
+func inspectMachoFat(b BinaryPlatform, data []byte) (BinaryPlatform, error) {
+	if len(data) < fatHeaderSize {
+		return b, nil
+	}
+	narch := binary.BigEndian.Uint32(data[4:8])
+	if narch == 0 || narch >= maxFatArch || len(data) < fatHeaderSize+int(narch)*fatArchSize {
+		// java class 파일도 동일한 magic(0xcafebabe) 을 사용하므로 바이너리로 취급하지 않는다
+		return b, nil
+	}
+
+	b.Format = FormatMacho
+	b.OS = "darwin"
+	b.Archs = make([]string, 0, narch)
+	for i := 0; i < int(narch); i++ {
+		offset := fatHeaderSize + i*fatArchSize
+		b.Archs = append(b.Archs, machoArch(macho.Cpu(binary.BigEndian.Uint32(data[offset:offset+4]))))
+	}
+	return b, nil
+}
+
+func elfOS(abi elf.OSABI) string {
+	switch abi {
+	case elf.ELFOSABI_FREEBSD:
+		return "freebsd"
+	case elf.ELFOSABI_NETBSD:
+		return "netbsd"
+	case elf.ELFOSABI_OPENBSD:
+		return "openbsd"
+	case elf.ELFOSABI_SOLARIS:
+		return "solaris"
+	}
+	// go 의 linux 바이너리는 ELFOSABI_NONE(SYSV) 로 마킹된다
+	return "linux"
+}
+
+func elfArch(machine elf.Machine, order binary.ByteOrder) string {
+	switch machine {
+	case elf.EM_X86_64:
+		return "amd64"
+	case elf.EM_386:
+		return "386"
+	case elf.EM_AARCH64:
+		return "arm64"
+	case elf.EM_ARM:
+		return "arm"
+	case elf.EM_PPC64:
+		if order == binary.LittleEndian {
+			return "ppc64le"
+		}
+		return "ppc64"
+	case elf.EM_S390:
+		return "s390x"
+	case elf.EM_RISCV:
+		return "riscv64"
+	case elf.EM_LOONGARCH:
+		return "loong64"
+	case elf.EM_MIPS:
+		if order == binary.LittleEndian {
+			return "mipsle"
+		}
+		return "mips"
+	}
+	return strings.ToLower(machine.String())
+}
+
+func machoArch(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.CpuAmd64:
+		return "amd64"
+	case macho.Cpu386:
+		return "386"
+	case macho.CpuArm64:
+		return "arm64"
+	case macho.CpuArm:
+		return "arm"
+	case macho.CpuPpc64:
+		return "ppc64"
+	}
+	return strings.ToLower(cpu.String())
+}
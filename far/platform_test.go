@@ -0,0 +1,117 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 2:10
+ */
+
+package far
+
+import (
+	"archive/zip"
+	"debug/macho"
+	"encoding/binary"
+	"fmt"
+	. "github.com/fatima-go/fatima-cmd/domain"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// buildFar 현재 실행중인 테스트 바이너리를 주어진 platform 디렉토리에 넣은 far 를 만든다
+func buildFar(t *testing.T, dirs ...string) string {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("fail to find executable : %s", err.Error())
+	}
+	bin, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatalf("fail to read executable : %s", err.Error())
+	}
+
+	farFile := filepath.Join(t.TempDir(), "sample.far")
+	f, err := os.Create(farFile)
+	if err != nil {
+		t.Fatalf("fail to create far : %s", err.Error())
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	defer zw.Close()
+	for _, dir := range dirs {
+		w, _ := zw.Create(fmt.Sprintf("/platform/%s/sample", dir))
+		_, _ = w.Write(bin)
+		w, _ = zw.Create(fmt.Sprintf("/platform/%s/sample.sh", dir))
+		_, _ = w.Write([]byte("#!/bin/sh\n"))
+	}
+	return farFile
+}
+
+func TestVerifyPlatform(t *testing.T) {
+	host := PlatformResp{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+
+	farFile := buildFar(t, host.String())
+	list, err := InspectPlatforms(farFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list))
+	assert.True(t, list[0].IsBinary())
+	assert.Equal(t, host.String(), list[0].Platform())
+	assert.False(t, list[1].IsBinary())
+	assert.Nil(t, VerifyPlatform(farFile, host))
+
+	// 다른 플랫폼 디렉토리에 잘못 들어간 바이너리
+	farFile = buildFar(t, host.String(), "plan9_mips")
+	assert.NotNil(t, VerifyPlatform(farFile, host))
+
+	// 타겟 호스트 플랫폼이 없는 경우
+	farFile = buildFar(t, host.String())
+	assert.NotNil(t, VerifyPlatform(farFile, PlatformResp{OS: host.OS, Architecture: "plan9"}))
+}
+
+func TestInspectHeader(t *testing.T) {
+	thin := make([]byte, 32)
+	binary.LittleEndian.PutUint32(thin[0:], macho.Magic64)
+	binary.LittleEndian.PutUint32(thin[4:], uint32(macho.CpuArm64))
+
+	fat := make([]byte, 8+2*20)
+	binary.BigEndian.PutUint32(fat[0:], macho.MagicFat)
+	binary.BigEndian.PutUint32(fat[4:], 2)
+	binary.BigEndian.PutUint32(fat[8:], uint32(macho.CpuAmd64))
+	binary.BigEndian.PutUint32(fat[28:], uint32(macho.CpuArm64))
+
+	// java class 파일 (major version 55)
+	class := []byte{0xca, 0xfe, 0xba, 0xbe, 0x00, 0x00, 0x00, 0x37}
+
+	farFile := filepath.Join(t.TempDir(), "sample.far")
+	f, err := os.Create(farFile)
+	assert.Nil(t, err)
+	zw := zip.NewWriter(f)
+	for name, data := range map[string][]byte{"a_thin": thin, "b_fat": fat, "c_class": class} {
+		w, _ := zw.Create("/platform/darwin_arm64/" + name)
+		_, _ = w.Write(data)
+	}
+	assert.Nil(t, zw.Close())
+	assert.Nil(t, f.Close())
+
+	list, err := InspectPlatforms(farFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(list))
+	assert.Equal(t, []string{"arm64"}, list[0].Archs)
+	assert.Equal(t, []string{"amd64", "arm64"}, list[1].Archs)
+	assert.False(t, list[2].IsBinary())
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 12:10
+ */
+
+package localproc
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/share"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const FolderLog = share.FatimaFolderLog
+
+// Launched Launch 로 기동한 프로세스
+type Launched struct {
+	Pid       int
+	StdoutLog string
+	StderrLog string
+	errOffset int64 // 기동 전 stderr 로그 파일 크기
+	done      chan struct{}
+	err       error
+}
+
+// Done 프로세스가 종료되면 닫힌다
+func (l *Launched) Done() <-chan struct{} {
+	return l.done
+}
+
+// Err 프로세스의 종료 결과. Done 이 닫힌 후에만 유효하다
+func (l *Launched) Err() error {
+	return l.err
+}
+
+// StartupStderr window 동안(혹은 프로세스가 종료될 때까지) 기다린 후 기동 이후 stderr 로그에 기록된 내용을 최대 max 바이트 리턴한다
+func (l *Launched) StartupStderr(window time.Duration, max int64) string {
+	select {
+	case <-l.done:
+	case <-time.After(window):
+	}
+
+	f, err := os.Open(l.StderrLog)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	_, err = f.Seek(l.errOffset, io.SeekStart)
+	if err != nil {
+		return ""
+	}
+	data, _ := io.ReadAll(io.LimitReader(f, max))
+	return string(data)
+}
+
+// Launch app 디렉토리에서 program 을 새로운 session 으로 기동한다.
+// stdout, stderr 는 app/<proc>/log/<proc>.out, <proc>.err 에 추가되며 기동한 프로세스의 pid 를 리턴한다
+func Launch(proc string, program string) (*Launched, error) {
+	appDir := AppDir(proc)
+	logDir := filepath.Join(appDir, FolderLog)
+	err := os.MkdirAll(logDir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create log dir : %s", err.Error())
+	}
+
+	l := &Launched{
+		StdoutLog: filepath.Join(logDir, proc+".out"),
+		StderrLog: filepath.Join(logDir, proc+".err"),
+		done:      make(chan struct{}),
+	}
+
+	stdout, err := os.OpenFile(l.StdoutLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer stdout.Close()
+
+	stderr, err := os.OpenFile(l.StderrLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer stderr.Close()
+	if info, err := stderr.Stat(); err == nil {
+		l.errOffset = info.Size()
+	}
+
+	cmd := exec.Command(program)
+	if info, err := os.Stat(program); err == nil && info.Mode().Perm()&0111 == 0 {
+		// 실행 권한이 없는 스크립트
+		cmd = exec.Command("bash", program)
+	}
+	cmd.Dir = appDir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+	l.Pid = cmd.Process.Pid
+
+	// 종료된 child 가 zombie 로 남아 살아있는 것으로 판단되지 않도록 wait 한다
+	go func() {
+		l.err = cmd.Wait()
+		close(l.done)
+	}()
+
+	return l, nil
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 6:00
+ */
+
+// Package localproc 로컬 fatima 프로세스의 pid 파일과 생존 여부를 확인한다.
+// pid 가 재사용된 경우를 구분하기 위해 /proc/<pid>/exe, cmdline 을 프로세스 이름과 비교한다
+package localproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+type Status int
+
+const (
+	// StatusNotRunning pid 파일이 없다
+	StatusNotRunning Status = iota
+	// StatusRunning pid 파일의 프로세스가 살아있고 해당 app 의 프로세스이다
+	StatusRunning
+	// StatusStale pid 파일은 있으나 프로세스가 없거나 다른 프로그램이 pid 를 재사용하고 있다
+	StatusStale
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusRunning:
+		return "RUNNING"
+	case StatusStale:
+		return "STALE"
+	}
+	return "NOT_RUNNING"
+}
+
+const procFsRoot = "/proc"
+
+type Process struct {
+	Name    string
+	Pid     int
+	PidFile string
+	Status  Status
+}
+
+func (p Process) IsRunning() bool {
+	return p.Status == StatusRunning
+}
+
+// AppDir $FATIMA_HOME/app/<proc>
+func AppDir(proc string) string {
+	return filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderApp, proc)
+}
+
+// PidFile $FATIMA_HOME/app/<proc>/proc/<proc>.pid
+func PidFile(proc string) string {
+	return filepath.Join(AppDir(proc), share.FatimaFolderAppProc, proc+".pid")
+}
+
+// ReadPid pid 파일을 읽는다. 파일이 없을 경우 os.IsNotExist 로 확인할 수 있는 에러를 리턴한다
+func ReadPid(pidFile string) (int, error) {
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		return 0, err
+	}
+
+	content := strings.Trim(string(data), "\r\n\t ")
+	pid, err := strconv.Atoi(content)
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid pid content : [%s]", content)
+	}
+	return pid, nil
+}
+
+// IsAlive kill(pid, 0) 으로 프로세스 존재 여부를 확인한다. 권한이 없는 경우(EPERM)도 살아있는 것으로 본다
+func IsAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// Matches pid 의 프로세스가 proc 의 프로그램인지 /proc/<pid>/exe, cmdline 으로 확인한다.
+// /proc 파일시스템이 없는 플랫폼에서는 확인할 수 없으므로 true 를 리턴한다
+func Matches(pid int, proc string) bool {
+	if !share.IsFileExist(procFsRoot + "/self") {
+		return true
+	}
+
+	dir := filepath.Join(procFsRoot, strconv.Itoa(pid))
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		exe = strings.TrimSuffix(exe, " (deleted)")
+		if isProgramOf(exe, proc) {
+			return true
+		}
+	}
+
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		// 다른 사용자의 프로세스 등 확인할 수 없는 경우
+		return !os.IsNotExist(err)
+	}
+
+	appDir := AppDir(proc)
+	realAppDir, _ := filepath.EvalSymlinks(appDir)
+	for _, arg := range bytes.Split(cmdline, []byte{0}) {
+		a := string(arg)
+		if len(a) == 0 {
+			continue
+		}
+		if isProgramOf(a, proc) || strings.Contains(a, appDir) || (len(realAppDir) > 0 && strings.Contains(a, realAppDir)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isProgramOf 실행 파일 이름이 proc 혹은 proc.sh 인지 확인한다
+func isProgramOf(file, proc string) bool {
+	base := filepath.Base(file)
+	return base == proc || base == proc+".sh"
+}
+
+// Lookup pid 파일로 proc 의 상태를 확인한다
+func Lookup(proc string) (Process, error) {
+	p := Process{Name: proc, PidFile: PidFile(proc), Status: StatusNotRunning}
+
+	pid, err := ReadPid(p.PidFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return p, err
+	}
+
+	p.Pid = pid
+	if IsAlive(pid) && Matches(pid, proc) {
+		p.Status = StatusRunning
+	} else {
+		p.Status = StatusStale
+	}
+	return p, nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 6:00
+ */
+
+package localproc
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePidFile(t *testing.T, proc string, content string) {
+	pidFile := PidFile(proc)
+	assert.Nil(t, os.MkdirAll(filepath.Dir(pidFile), 0755))
+	assert.Nil(t, os.WriteFile(pidFile, []byte(content), 0644))
+}
+
+func TestLookup(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+
+	exe, err := os.Executable()
+	assert.Nil(t, err)
+	self := filepath.Base(exe)
+
+	p, err := Lookup(self)
+	assert.Nil(t, err)
+	assert.Equal(t, StatusNotRunning, p.Status)
+
+	writePidFile(t, self, fmt.Sprintf("%d\n", os.Getpid()))
+	p, err = Lookup(self)
+	assert.Nil(t, err)
+	assert.True(t, p.IsRunning())
+	assert.Equal(t, os.Getpid(), p.Pid)
+
+	// 살아있는 pid 이지만 다른 프로그램
+	writePidFile(t, "sample", fmt.Sprintf("%d", os.Getpid()))
+	p, err = Lookup("sample")
+	assert.Nil(t, err)
+	if share.IsFileExist(procFsRoot + "/self") {
+		assert.Equal(t, StatusStale, p.Status)
+	}
+
+	// 존재하지 않는 pid
+	writePidFile(t, "sample", "2147483646")
+	p, err = Lookup("sample")
+	assert.Nil(t, err)
+	assert.Equal(t, StatusStale, p.Status)
+
+	writePidFile(t, "sample", "abc")
+	_, err = Lookup("sample")
+	assert.NotNil(t, err)
+}
+
+func TestStat(t *testing.T) {
+	if !share.IsFileExist(procFsRoot + "/self") {
+		t.Skip("no /proc filesystem")
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer l.Close()
+
+	s, err := Stat(os.Getpid())
+	assert.Nil(t, err)
+	assert.True(t, s.RSS > 0)
+	assert.True(t, s.Threads > 0)
+	assert.True(t, s.OpenFds > 0)
+	assert.True(t, s.Uptime() < time.Hour)
+	assert.Contains(t, s.ListenPorts, l.Addr().(*net.TCPAddr).Port)
+}
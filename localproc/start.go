@@ -0,0 +1,225 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 11:30
+ */
+
+package localproc
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dialTimeout = time.Second
+
+// WaitStarted pid 파일에 prevPid 와 다른, 살아있는 프로세스의 pid 가 기록될 때까지 기다린다
+func WaitStarted(proc string, prevPid int, timeout time.Duration) (int, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		process, err := Lookup(proc)
+		if err == nil && process.IsRunning() && process.Pid != prevPid {
+			return process.Pid, nil
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("pid file is not created within %s", timeout)
+		}
+		time.Sleep(PollInterval)
+	}
+}
+
+// WaitStable pid 가 duration 동안 계속 살아있는지 확인한다
+func WaitStable(pid int, duration time.Duration) error {
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) {
+		if !IsAlive(pid) {
+			return fmt.Errorf("pid %d terminated within %s", pid, duration)
+		}
+		time.Sleep(PollInterval)
+	}
+	return nil
+}
+
+// WaitPort addr 로 tcp 연결이 가능할 때까지 기다린다
+func WaitPort(addr string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+		if err == nil {
+			_ = conn.Close()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("port %s is not opened within %s", addr, timeout)
+		}
+		time.Sleep(PollInterval)
+	}
+}
+
+const (
+	// DefaultPorts opm 프로그램의 readiness 를 확인하는 기본 port 목록
+	DefaultPorts = "jupiter:9190"
+	// PortsUsage --ports 옵션 설명
+	PortsUsage = "ports to check readiness. e.g) jupiter:9190,juno:9180. empty to skip"
+)
+
+// ParsePorts "jupiter:9190,juno:9180" 형식의 프로세스별 port 목록을 파싱한다
+func ParsePorts(value string) (map[string]int, error) {
+	ports := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		name, port, ok := strings.Cut(item, ":")
+		p, err := strconv.Atoi(port)
+		if !ok || err != nil || p <= 0 {
+			return nil, fmt.Errorf("invalid port : %s", item)
+		}
+		ports[name] = p
+	}
+	return ports, nil
+}
+
+type StartOutcome int
+
+const (
+	StartStarted        StartOutcome = iota // 기동 후 readiness 확인까지 완료했다
+	StartAlreadyRunning                     // 이미 실행중이다
+	StartNotInstalled                       // 실행 파일이 없다
+	StartFailed                             // 기동에 실패했다
+)
+
+func (o StartOutcome) String() string {
+	switch o {
+	case StartAlreadyRunning:
+		return "ALREADY RUNNING"
+	case StartNotInstalled:
+		return "NOT INSTALLED"
+	case StartFailed:
+		return "FAILED"
+	}
+	return "STARTED"
+}
+
+const (
+	// DefaultStableDuration 기동 후 프로세스가 살아있어야 하는 시간
+	DefaultStableDuration = 2 * time.Second
+	// startupWindow 기동 직후 stderr 를 보여주기 위해 기다리는 시간
+	startupWindow    = time.Second
+	maxStartupStderr = 4096
+)
+
+type StartOptions struct {
+	Timeout        time.Duration // pid 파일 생성, port open 각각의 timeout
+	StableDuration time.Duration
+	Port           int // 0 이면 port 를 확인하지 않는다
+}
+
+type StartResult struct {
+	Name    string
+	Pid     int
+	Outcome StartOutcome
+}
+
+// Program $FATIMA_HOME/app/<proc>/<proc> 또는 <proc>.sh 실행 파일 경로를 찾는다
+func Program(proc string) (string, bool) {
+	program := filepath.Join(AppDir(proc), proc)
+	for _, p := range []string{program, program + ".sh"} {
+		if fi, err := os.Stat(p); err == nil && fi.Mode().IsRegular() {
+			return p, true
+		}
+	}
+	return program, false
+}
+
+// Start 프로그램을 기동하고 pid 파일 생성, 프로세스 생존, (지정된 경우) port open 을 확인한다
+func Start(proc string, options StartOptions) (StartResult, error) {
+	result := StartResult{Name: proc, Outcome: StartFailed}
+
+	process, err := Lookup(proc)
+	if err != nil {
+		return result, err
+	}
+	if process.IsRunning() {
+		fmt.Printf("process %s is already running. pid=%d\n", proc, process.Pid)
+		result.Pid = process.Pid
+		result.Outcome = StartAlreadyRunning
+		return result, nil
+	}
+	if process.Status == StatusStale {
+		fmt.Printf("ignore stale pid file %s. pid %d\n", process.PidFile, process.Pid)
+	}
+
+	program, ok := Program(proc)
+	if !ok {
+		result.Outcome = StartNotInstalled
+		return result, nil
+	}
+
+	launched, err := Launch(proc, program)
+	if err != nil {
+		return result, err
+	}
+
+	startupErr := launched.StartupStderr(startupWindow, maxStartupStderr)
+	if len(startupErr) > 0 {
+		fmt.Printf("%s\n", startupErr)
+	}
+	fmt.Printf("%s log : %s, %s\n", proc, launched.StdoutLog, launched.StderrLog)
+
+	select {
+	case <-launched.Done():
+		// 스크립트가 백그라운드로 프로그램을 띄우고 종료하는 경우도 있으므로 정상 종료는 pid 파일로 판단한다
+		if launched.Err() != nil {
+			result.Pid = launched.Pid
+			return result, fmt.Errorf("process exited : %s", launched.Err().Error())
+		}
+	default:
+	}
+
+	pid, err := WaitStarted(proc, process.Pid, options.Timeout)
+	if err != nil {
+		return result, err
+	}
+	result.Pid = pid
+
+	stable := options.StableDuration
+	if stable <= 0 {
+		stable = DefaultStableDuration
+	}
+	err = WaitStable(pid, stable)
+	if err != nil {
+		return result, err
+	}
+
+	if options.Port > 0 {
+		fmt.Printf("wait until %s port %d is opened\n", proc, options.Port)
+		err = WaitPort(fmt.Sprintf("127.0.0.1:%d", options.Port), options.Timeout)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.Outcome = StartStarted
+	return result, nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오후 4:30
+ */
+
+package localproc
+
+import (
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// installProgram app/<proc>/<proc>.sh 를 만든다. body 는 app 디렉토리에서 bash 로 실행된다
+func installProgram(t *testing.T, proc string, body string) {
+	assert.Nil(t, os.MkdirAll(filepath.Join(AppDir(proc), share.FatimaFolderAppProc), 0755))
+	script := "#!/bin/bash\n" + body + "\n"
+	assert.Nil(t, os.WriteFile(filepath.Join(AppDir(proc), proc+".sh"), []byte(script), 0755))
+}
+
+func killOnCleanup(t *testing.T, pid int) {
+	t.Cleanup(func() {
+		if pid > 0 {
+			_ = syscall.Kill(pid, syscall.SIGKILL)
+		}
+	})
+}
+
+func TestStart(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+	options := StartOptions{Timeout: 3 * time.Second, StableDuration: 300 * time.Millisecond}
+
+	result, err := Start("sample", options)
+	assert.Nil(t, err)
+	assert.Equal(t, StartNotInstalled, result.Outcome)
+
+	installProgram(t, "sample", "echo $$ > proc/sample.pid\nexec -a sample sleep 30")
+	result, err = Start("sample", options)
+	killOnCleanup(t, result.Pid)
+	assert.Nil(t, err)
+	assert.Equal(t, StartStarted, result.Outcome)
+	assert.True(t, IsAlive(result.Pid))
+
+	again, err := Start("sample", options)
+	assert.Nil(t, err)
+	assert.Equal(t, StartAlreadyRunning, again.Outcome)
+	assert.Equal(t, result.Pid, again.Pid)
+}
+
+func TestStartReadiness(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+
+	// pid 파일을 만들지 않고 종료하는 프로그램
+	installProgram(t, "nopid", "exit 0")
+	result, err := Start("nopid", StartOptions{Timeout: 500 * time.Millisecond})
+	assert.NotNil(t, err)
+	assert.Equal(t, StartFailed, result.Outcome)
+
+	// 에러로 종료하는 프로그램
+	installProgram(t, "broken", "echo broken >&2\nexit 3")
+	result, err = Start("broken", StartOptions{Timeout: 500 * time.Millisecond})
+	assert.NotNil(t, err)
+	assert.Equal(t, StartFailed, result.Outcome)
+
+	// stable duration 안에 종료하는 프로그램
+	installProgram(t, "short", "echo $$ > proc/short.pid\nexec -a short sleep 0.5")
+	result, err = Start("short", StartOptions{Timeout: 3 * time.Second, StableDuration: 2 * time.Second})
+	killOnCleanup(t, result.Pid)
+	assert.NotNil(t, err)
+	assert.Equal(t, StartFailed, result.Outcome)
+
+	// port 가 열리지 않는 프로그램
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	assert.Nil(t, listener.Close())
+
+	installProgram(t, "noport", "echo $$ > proc/noport.pid\nexec -a noport sleep 30")
+	result, err = Start("noport", StartOptions{Timeout: 500 * time.Millisecond, StableDuration: 100 * time.Millisecond, Port: port})
+	killOnCleanup(t, result.Pid)
+	assert.NotNil(t, err)
+	assert.Equal(t, StartFailed, result.Outcome)
+
+	// port 가 열려 있으면 기동 완료
+	listener, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	port = listener.Addr().(*net.TCPAddr).Port
+
+	installProgram(t, "ready", "echo $$ > proc/ready.pid\nexec -a ready sleep 30")
+	result, err = Start("ready", StartOptions{Timeout: time.Second, StableDuration: 100 * time.Millisecond, Port: port})
+	killOnCleanup(t, result.Pid)
+	assert.Nil(t, err)
+	assert.Equal(t, StartStarted, result.Outcome)
+}
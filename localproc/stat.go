@@ -0,0 +1,193 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 8:30
+ */
+
+package localproc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks /proc/<pid>/stat 의 시간 단위. 리눅스는 대부분 USER_HZ 100 을 사용한다
+const clockTicks = 100
+
+const tcpStateListen = "0A"
+
+type ProcStat struct {
+	StartTime   time.Time `json:"start_time"`
+	RSS         int64     `json:"rss"` // bytes
+	Threads     int       `json:"threads"`
+	OpenFds     int       `json:"open_fds"`
+	ListenPorts []int     `json:"listen_ports"`
+}
+
+func (s ProcStat) Uptime() time.Duration {
+	return time.Since(s.StartTime).Truncate(time.Second)
+}
+
+// Stat /proc 에서 프로세스의 시작시간, RSS, thread, fd 개수, listen 중인 tcp port 를 읽는다
+func Stat(pid int) (ProcStat, error) {
+	s := ProcStat{ListenPorts: make([]int, 0)}
+	dir := filepath.Join(procFsRoot, strconv.Itoa(pid))
+
+	startTime, err := readStartTime(dir)
+	if err != nil {
+		return s, err
+	}
+	s.StartTime = startTime
+
+	err = readStatus(dir, &s)
+	if err != nil {
+		return s, err
+	}
+
+	fds, err := os.ReadDir(filepath.Join(dir, "fd"))
+	if err != nil {
+		// 다른 사용자의 프로세스는 fd 를 볼 수 없다
+		return s, nil
+	}
+	s.OpenFds = len(fds)
+
+	inodes := make(map[string]bool)
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(dir, "fd", fd.Name()))
+		if err == nil && strings.HasPrefix(link, "socket:[") {
+			inodes[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")] = true
+		}
+	}
+
+	ports := make(map[int]bool)
+	for _, name := range []string{"tcp", "tcp6"} {
+		readListenPorts(filepath.Join(dir, "net", name), inodes, ports)
+	}
+	for p := range ports {
+		s.ListenPorts = append(s.ListenPorts, p)
+	}
+	sort.Ints(s.ListenPorts)
+	return s, nil
+}
+
+// readStartTime /proc/<pid>/stat 의 starttime(22번째 필드) 과 /proc/stat 의 btime 으로 기동 시각을 구한다
+func readStartTime(dir string) (time.Time, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// comm 필드에 공백이나 괄호가 있을 수 있으므로 마지막 ')' 이후부터 파싱한다
+	idx := strings.LastIndex(string(data), ")")
+	if idx < 0 {
+		return time.Time{}, fmt.Errorf("invalid stat format")
+	}
+	fields := strings.Fields(string(data)[idx+1:])
+	// fields[0] 은 3번째 필드(state) 이다
+	if len(fields) < 20 {
+		return time.Time{}, fmt.Errorf("invalid stat format")
+	}
+	ticks, err := strconv.ParseInt(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid starttime : %s", err.Error())
+	}
+
+	bootTime, err := readBootTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(bootTime+ticks/clockTicks, 0), nil
+}
+
+func readBootTime() (int64, error) {
+	f, err := os.Open(filepath.Join(procFsRoot, "stat"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "btime ") {
+			return strconv.ParseInt(strings.TrimSpace(line[len("btime "):]), 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("not found btime")
+}
+
+// readStatus /proc/<pid>/status 의 VmRSS, Threads 를 읽는다
+func readStatus(dir string, s *ProcStat) error {
+	f, err := os.Open(filepath.Join(dir, "status"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "VmRSS":
+			kb, _ := strconv.ParseInt(fields[0], 10, 64)
+			s.RSS = kb * 1024
+		case "Threads":
+			s.Threads, _ = strconv.Atoi(fields[0])
+		}
+	}
+	return scanner.Err()
+}
+
+// readListenPorts /proc/net/tcp 형식의 파일에서 inodes 에 해당하는 LISTEN 소켓의 port 를 찾는다
+func readListenPorts(file string, inodes map[string]bool, ports map[int]bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpStateListen || !inodes[fields[9]] {
+			continue
+		}
+		_, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		port, err := strconv.ParseInt(hexPort, 16, 32)
+		if err == nil {
+			ports[int(port)] = true
+		}
+	}
+}
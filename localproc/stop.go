@@ -0,0 +1,139 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 11:00
+ */
+
+package localproc
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+type StopOutcome int
+
+const (
+	StopNotRunning StopOutcome = iota // pid 파일이 없다
+	StopStale                         // stale pid 파일을 정리했다
+	StopTerminated                    // SIGTERM 으로 종료되었다
+	StopKilled                        // timeout 이후 SIGKILL 로 종료되었다
+	StopFailed                        // SIGKILL 이후에도 종료되지 않았다
+)
+
+func (o StopOutcome) String() string {
+	switch o {
+	case StopStale:
+		return "STALE PID CLEANED"
+	case StopTerminated:
+		return "STOPPED"
+	case StopKilled:
+		return "KILLED"
+	case StopFailed:
+		return "FAILED"
+	}
+	return "NOT RUNNING"
+}
+
+const (
+	PollInterval = 200 * time.Millisecond
+	killWaitTime = 5 * time.Second
+)
+
+type StopResult struct {
+	Name    string
+	Pid     int
+	Outcome StopOutcome
+	Elapsed time.Duration
+}
+
+// WaitExit pid 가 종료될 때까지 timeout 동안 기다린다
+func WaitExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if !IsAlive(pid) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(PollInterval)
+	}
+}
+
+// Stop SIGTERM 을 보내고 timeout 동안 종료되지 않으면 SIGKILL 을 보낸다.
+// 종료된 프로세스나 stale pid 파일이 남아 있으면 제거한다.
+// Elapsed 는 defer 에서 기록하므로 named result 를 사용한다
+func Stop(proc string, timeout time.Duration) (result StopResult, err error) {
+	result = StopResult{Name: proc}
+	process, err := Lookup(proc)
+	if err != nil {
+		result.Outcome = StopFailed
+		return result, err
+	}
+	result.Pid = process.Pid
+
+	switch process.Status {
+	case StatusNotRunning:
+		result.Outcome = StopNotRunning
+		return result, nil
+	case StatusStale:
+		result.Outcome = StopStale
+		return result, removePidFile(process.PidFile)
+	}
+
+	start := time.Now()
+	defer func() {
+		result.Elapsed = time.Since(start).Truncate(time.Millisecond)
+	}()
+
+	err = syscall.Kill(process.Pid, syscall.SIGTERM)
+	if err != nil {
+		result.Outcome = StopFailed
+		return result, fmt.Errorf("fail to send SIGTERM to %d : %s", process.Pid, err.Error())
+	}
+
+	if WaitExit(process.Pid, timeout) {
+		result.Outcome = StopTerminated
+		return result, removePidFile(process.PidFile)
+	}
+
+	err = syscall.Kill(process.Pid, syscall.SIGKILL)
+	if err != nil && IsAlive(process.Pid) {
+		result.Outcome = StopFailed
+		return result, fmt.Errorf("fail to send SIGKILL to %d : %s", process.Pid, err.Error())
+	}
+
+	if !WaitExit(process.Pid, killWaitTime) {
+		result.Outcome = StopFailed
+		return result, fmt.Errorf("pid %d still alive after SIGKILL", process.Pid)
+	}
+
+	result.Outcome = StopKilled
+	return result, removePidFile(process.PidFile)
+}
+
+// removePidFile 프로세스가 직접 정리했을 수 있으므로 파일이 없는 경우는 무시한다
+func removePidFile(pidFile string) error {
+	err := os.Remove(pidFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("fail to remove pid file : %s", err.Error())
+	}
+	return nil
+}
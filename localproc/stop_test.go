@@ -0,0 +1,107 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 11:00
+ */
+
+package localproc
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// startChild 자식 프로세스를 기동하고 pid 파일을 만든다. 종료된 자식이 zombie 로 남지 않도록 wait 한다
+func startChild(t *testing.T, proc string, args ...string) int {
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		t.Skipf("fail to start %s : %s", args[0], err.Error())
+	}
+	go func() {
+		_ = cmd.Wait()
+	}()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+	})
+
+	writePidFile(t, proc, fmt.Sprintf("%d", cmd.Process.Pid))
+	return cmd.Process.Pid
+}
+
+func TestStop(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+
+	result, err := Stop("sleep", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, StopNotRunning, result.Outcome)
+
+	pid := startChild(t, "sleep", "sleep", "30")
+	result, err = Stop("sleep", 3*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, StopTerminated, result.Outcome)
+	assert.False(t, IsAlive(pid))
+	assert.False(t, share.IsFileExist(PidFile("sleep")))
+
+	// SIGTERM 을 무시하는 프로세스
+	startChild(t, "sh", "sh", "-c", "trap '' TERM; while :; do sleep 0.1; done")
+	time.Sleep(200 * time.Millisecond)
+	result, err = Stop("sh", 500*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, StopKilled, result.Outcome)
+	assert.True(t, result.Elapsed >= 500*time.Millisecond)
+
+	// stale pid 파일
+	writePidFile(t, "sleep", "2147483646")
+	result, err = Stop("sleep", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, StopStale, result.Outcome)
+	assert.False(t, share.IsFileExist(PidFile("sleep")))
+}
+
+func TestLaunch(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+	assert.Nil(t, os.MkdirAll(AppDir("sample"), 0755))
+
+	// stderr 를 닫지 않고 계속 실행되는 프로그램
+	program := filepath.Join(AppDir("sample"), "sample.sh")
+	script := "#!/bin/sh\necho starting >&2\necho $$ > proc.pid\nexec sleep 30\n"
+	assert.Nil(t, os.WriteFile(program, []byte(script), 0755))
+
+	l, err := Launch("sample", program)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = syscall.Kill(l.Pid, syscall.SIGKILL)
+	})
+
+	start := time.Now()
+	assert.Equal(t, "starting\n", l.StartupStderr(500*time.Millisecond, 1024))
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	data, _ := os.ReadFile(filepath.Join(AppDir("sample"), "proc.pid"))
+	assert.Equal(t, fmt.Sprintf("%d\n", l.Pid), string(data))
+	assert.True(t, IsAlive(l.Pid))
+
+	sid, _, _ := syscall.RawSyscall(syscall.SYS_GETSID, uintptr(l.Pid), 0, 0)
+	assert.Equal(t, uintptr(l.Pid), sid)
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 10:00
+ */
+
+package revision
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+type DedupeResult struct {
+	Files     int   // 검사한 파일 수
+	Linked    int   // hardlink 로 교체한 파일 수
+	Reclaimed int64 // 확보한 용량 (bytes)
+}
+
+// dedupeFile hardlink 로 합칠 수 있는지 판단하기 위한 파일 정보
+type dedupeFile struct {
+	path  string
+	size  int64
+	mode  os.FileMode
+	dev   uint64
+	ino   uint64
+	uid   uint32
+	gid   uint32
+	nlink uint64
+}
+
+// canShare 같은 파일시스템이고 권한, 소유자가 같아야 hardlink 로 합칠 수 있다.
+// hardlink 는 inode 의 권한과 소유자를 공유하기 때문이다
+func (f dedupeFile) canShare(o dedupeFile) bool {
+	return f.dev == o.dev && f.size == o.size && f.mode == o.mode && f.uid == o.uid && f.gid == o.gid
+}
+
+// Dedupe procRevisionDir($FATIMA_HOME/app/revision/<proc>) 하위 revision 들 사이에서 내용이 같은 파일을 hardlink 로 합친다.
+// proc, log 폴더와 설정 파일은 제외하며 다른 파일시스템의 파일은 합치지 않는다. dryRun 이면 확보할 수 있는 용량만 계산한다
+func Dedupe(procRevisionDir string, dryRun bool) (DedupeResult, error) {
+	result := DedupeResult{}
+
+	bySize := make(map[int64][]dedupeFile)
+	err := filepath.WalkDir(procRevisionDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// procRevisionDir/<revision>/ 이하의 경로
+		rel, err := filepath.Rel(procRevisionDir, path)
+		if err != nil {
+			return err
+		}
+		_, revRel, _ := strings.Cut(filepath.ToSlash(rel), "/")
+		if d.IsDir() {
+			if len(revRel) > 0 && IsRuntimePath(revRel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// 설정 파일은 운영중에 수정될 수 있으므로 변경되지 않는 배포 파일만 합친다
+		if !d.Type().IsRegular() || d.Name() == ManifestFileName || d.Name() == PinFileName ||
+			IsRuntimePath(revRel) || IsConfigFile(d.Name()) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		st, ok := info.Sys().(*syscall.Stat_t)
+		if !ok || info.Size() == 0 {
+			return nil
+		}
+
+		result.Files++
+		f := dedupeFile{path: path, size: info.Size(), mode: info.Mode(), dev: uint64(st.Dev), ino: uint64(st.Ino),
+			uid: st.Uid, gid: st.Gid, nlink: uint64(st.Nlink)}
+		bySize[f.size] = append(bySize[f.size], f)
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, files := range bySize {
+		if len(files) < 2 {
+			continue
+		}
+
+		// 같은 크기의 파일을 hash 로 다시 분류한다
+		byHash := make(map[string][]dedupeFile)
+		for _, f := range files {
+			sum, err := HashFile(f.path)
+			if err != nil {
+				return result, err
+			}
+			byHash[sum] = append(byHash[sum], f)
+		}
+
+		for _, same := range byHash {
+			err = linkSame(same, dryRun, &result)
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// linkSame 내용이 같은 파일들을 조건이 맞는 첫번째 파일의 hardlink 로 교체한다.
+// 교체한 파일의 inode 를 더 이상 link 하는 곳이 없을 때만 확보한 용량으로 계산한다
+func linkSame(files []dedupeFile, dryRun bool, result *DedupeResult) error {
+	links := make(map[inodeKey]uint64)
+	for _, f := range files {
+		links[f.key()] = f.nlink
+	}
+
+	sources := make([]dedupeFile, 0)
+	for _, f := range files {
+		var source *dedupeFile
+		for i := range sources {
+			if sources[i].canShare(f) {
+				source = &sources[i]
+				break
+			}
+		}
+
+		if source == nil {
+			sources = append(sources, f)
+			continue
+		}
+		if source.ino == f.ino {
+			continue
+		}
+
+		key := f.key()
+		if !dryRun {
+			// 이전 실행이나 다른 곳에서 link 되었을 수 있으므로 교체 직전의 link 수를 사용한다
+			if n, ok := linkCount(f.path, f.ino); ok {
+				links[key] = n
+			}
+			err := replaceWithLink(source.path, f.path)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Linked++
+		if links[key] > 0 {
+			links[key]--
+		}
+		if links[key] == 0 {
+			result.Reclaimed += f.size
+		}
+	}
+	return nil
+}
+
+type inodeKey struct {
+	dev uint64
+	ino uint64
+}
+
+func (f dedupeFile) key() inodeKey {
+	return inodeKey{dev: f.dev, ino: f.ino}
+}
+
+// linkCount path 가 아직 ino 를 가리키고 있으면 현재 link 수를 리턴한다
+func linkCount(path string, ino uint64) (uint64, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || uint64(st.Ino) != ino {
+		return 0, false
+	}
+	return uint64(st.Nlink), true
+}
+
+// replaceWithLink 임시 hardlink 를 만든 후 rename 으로 교체한다. 교체 중간에 파일이 없어지는 순간이 없다
+func replaceWithLink(source, target string) error {
+	tmp := filepath.Join(filepath.Dir(target), fmt.Sprintf(".%s.%d.dedupe", filepath.Base(target), os.Getpid()))
+	_ = os.Remove(tmp)
+	err := os.Link(source, tmp)
+	if err != nil {
+		return fmt.Errorf("fail to link %s : %s", target, err.Error())
+	}
+
+	err = os.Rename(tmp, target)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("fail to replace %s : %s", target, err.Error())
+	}
+	return nil
+}
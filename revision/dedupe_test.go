@@ -0,0 +1,139 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 10:00
+ */
+
+package revision
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rev, name, body string, mode os.FileMode) string {
+		p := filepath.Join(dir, rev, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.Nil(t, os.WriteFile(p, []byte(body), mode))
+		assert.Nil(t, os.Chmod(p, mode))
+		return p
+	}
+
+	a := write("2023.01.01-10.00_R001", "sample", "binary", 0755)
+	b := write("2023.01.02-10.00_R002", "sample", "binary", 0755)
+	write("2023.01.02-10.00_R002", "sample.yaml", "conf 2", 0644)
+	write("2023.01.01-10.00_R001", "sample.yaml", "conf 1", 0644)
+	// 내용은 같지만 권한이 다른 파일은 합치지 않는다
+	c := write("2023.01.03-10.00_R003", "sample", "binary", 0750)
+	// 설정 파일과 로그는 내용이 같아도 합치지 않는다
+	ya := write("2023.01.01-10.00_R001", "log.xml", "<log/>", 0644)
+	yb := write("2023.01.02-10.00_R002", "log.xml", "<log/>", 0644)
+	la := write("2023.01.01-10.00_R001", "log/sample.out", "started", 0644)
+	lb := write("2023.01.02-10.00_R002", "log/sample.out", "started", 0644)
+
+	result, err := Dedupe(dir, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Linked)
+	assert.Equal(t, int64(6), result.Reclaimed)
+
+	result, err = Dedupe(dir, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.Files)
+	assert.Equal(t, 1, result.Linked)
+
+	sa, _ := os.Stat(a)
+	sb, _ := os.Stat(b)
+	sc, _ := os.Stat(c)
+	assert.True(t, os.SameFile(sa, sb))
+	assert.False(t, os.SameFile(sa, sc))
+	for _, pair := range [][2]string{{ya, yb}, {la, lb}} {
+		s1, _ := os.Stat(pair[0])
+		s2, _ := os.Stat(pair[1])
+		assert.False(t, os.SameFile(s1, s2))
+	}
+
+	// 이미 합쳐진 파일은 다시 link 하지 않는다
+	result, err = Dedupe(dir, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Linked)
+}
+
+func TestDedupeReclaimed(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rev string) string {
+		p := filepath.Join(dir, rev, "sample")
+		assert.Nil(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.Nil(t, os.WriteFile(p, []byte("binary"), 0755))
+		assert.Nil(t, os.Chmod(p, 0755))
+		return p
+	}
+
+	write("2023.01.01-10.00_R001")
+	b := write("2023.01.02-10.00_R002")
+	// 이전 실행에서 이미 link 된 파일
+	c := filepath.Join(dir, "2023.01.03-10.00_R003", "sample")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(c), 0755))
+	assert.Nil(t, os.Link(b, c))
+
+	// R002, R003 이 공유하는 inode 하나만 확보된다
+	result, err := Dedupe(dir, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Linked)
+	assert.Equal(t, int64(6), result.Reclaimed)
+
+	result, err = Dedupe(dir, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Linked)
+	assert.Equal(t, int64(6), result.Reclaimed)
+
+	result, err = Dedupe(dir, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Linked)
+	assert.Equal(t, int64(0), result.Reclaimed)
+}
+
+func TestDedupeEditIsolated(t *testing.T) {
+	dir := t.TempDir()
+	r1 := filepath.Join(dir, "2023.01.01-10.00_R001")
+	r2 := filepath.Join(dir, "2023.01.02-10.00_R002")
+	for _, rev := range []string{r1, r2} {
+		assert.Nil(t, os.MkdirAll(rev, 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(rev, "start.run"), []byte("#!/bin/sh\n"), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(rev, "sample"), []byte("binary"), 0755))
+	}
+
+	result, err := Dedupe(dir, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Linked)
+
+	// R002 의 파일을 수정해도 R001 은 바뀌지 않아야 한다
+	assert.Nil(t, WriteFile(filepath.Join(r2, "start.run"), []byte("#!/bin/sh\necho edited\n"), 0755))
+	src := filepath.Join(t.TempDir(), "sample")
+	assert.Nil(t, os.WriteFile(src, []byte("binary v2"), 0755))
+	assert.Nil(t, CopyFile(src, filepath.Join(r2, "sample")))
+
+	data, _ := os.ReadFile(filepath.Join(r1, "start.run"))
+	assert.Equal(t, "#!/bin/sh\n", string(data))
+	data, _ = os.ReadFile(filepath.Join(r1, "sample"))
+	assert.Equal(t, "binary", string(data))
+	info, _ := os.Stat(filepath.Join(r2, "sample"))
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
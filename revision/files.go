@@ -0,0 +1,113 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오후 2:10
+ */
+
+package revision
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/share"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigSuffixList 설정 파일 확장자
+var ConfigSuffixList = [...]string{"yaml", "yml", "properties", "json", "xml", "sh"}
+
+// IsConfigFile 설정 파일인지 확인한다
+func IsConfigFile(name string) bool {
+	for _, s := range ConfigSuffixList {
+		if strings.HasSuffix(name, "."+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRuntimePath revision 기준 상대경로 rel 이 프로세스 실행 중에 생성되는
+// pid 파일(proc) 이나 로그(log) 폴더인지 확인한다
+func IsRuntimePath(rel string) bool {
+	first := strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+	return first == share.FatimaFolderAppProc || first == share.FatimaFolderLog
+}
+
+// ReplaceFile r 의 내용을 같은 디렉토리의 임시 파일에 쓴 후 dest 로 rename 한다.
+// dedupe 로 다른 revision 과 hardlink 된 파일을 직접 수정하면 모든 revision 이 함께 바뀌기 때문에
+// revision 디렉토리의 파일은 항상 새 inode 로 교체한다
+func ReplaceFile(dest string, r io.Reader, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	err = tmp.Chmod(perm)
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	err = tmp.Sync()
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), dest)
+	if err != nil {
+		return fmt.Errorf("fail to rename to %s : %s", dest, err.Error())
+	}
+	return nil
+}
+
+// WriteFile data 를 ReplaceFile 로 기록한다
+func WriteFile(dest string, data []byte, perm os.FileMode) error {
+	return ReplaceFile(dest, strings.NewReader(string(data)), perm)
+}
+
+// CopyFile src 를 dest 로 복사한다. dest 가 이미 있으면 dest 의 권한을 유지한다.
+// dest 는 hardlink 되어 있을 수 있으므로 덮어쓰지 않고 새 파일로 교체한다
+func CopyFile(src, dest string) error {
+	from, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer from.Close()
+
+	info, err := from.Stat()
+	if err != nil {
+		return err
+	}
+	mode := info.Mode().Perm()
+	if destInfo, err := os.Stat(dest); err == nil {
+		mode = destInfo.Mode().Perm()
+	}
+
+	return ReplaceFile(dest, from, mode)
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 5:30
+ */
+
+package revision
+
+import (
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"path/filepath"
+)
+
+// LinkSwitch app link 를 변경한 결과. 이전 target 으로 되돌릴 수 있다
+type LinkSwitch struct {
+	Link     string
+	Target   string
+	Previous string // 변경 전 link target. link 가 없었으면 빈 문자열
+}
+
+// Revert app link 를 변경 전 target 으로 되돌린다. 변경 전에 link 가 없었으면 link 를 제거한다
+func (s LinkSwitch) Revert() error {
+	if len(s.Previous) == 0 {
+		err := os.Remove(s.Link)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	_, err := SwitchLink(s.Link, s.Previous)
+	return err
+}
+
+// SwitchLink 임시 symlink 를 만든 후 rename(2) 으로 link 를 교체한다.
+// 교체 중간에 link 가 존재하지 않는 순간이 없으며 실패하면 기존 link 는 그대로 남는다
+func SwitchLink(link, target string) (LinkSwitch, error) {
+	s := LinkSwitch{Link: link, Target: target}
+
+	info, err := os.Lstat(link)
+	if err == nil {
+		if info.Mode()&os.ModeSymlink == 0 {
+			return s, fmt.Errorf("%s is not symbolic link", link)
+		}
+		s.Previous, err = os.Readlink(link)
+		if err != nil {
+			return s, fmt.Errorf("fail to read link %s : %s", link, err.Error())
+		}
+	} else if !os.IsNotExist(err) {
+		return s, err
+	}
+
+	tmpLink := filepath.Join(filepath.Dir(link), fmt.Sprintf(".%s.%d.tmp", filepath.Base(link), os.Getpid()))
+	_ = os.Remove(tmpLink)
+	err = os.Symlink(target, tmpLink)
+	if err != nil {
+		return s, fmt.Errorf("fail to create link : %s", err.Error())
+	}
+
+	err = os.Rename(tmpLink, link)
+	if err != nil {
+		_ = os.Remove(tmpLink)
+		return s, fmt.Errorf("fail to replace link : %s", err.Error())
+	}
+
+	return s, nil
+}
+
+// AppLink $FATIMA_HOME/app/<proc>
+func AppLink(proc string) string {
+	return filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderApp, proc)
+}
+
+// Switch revDir 에 overlay 를 적용한 후 app link 를 revDir 로 변경한다.
+// manifest 가 없는 revision 은 overlay 를 적용하기 전의 파일로 baseline manifest 를 생성한다.
+// 배포나 lcproc 등 revision 을 변경하는 곳은 모두 이 함수를 사용해야 overlay 가 누락되지 않는다
+func Switch(proc string, revDir string) (LinkSwitch, OverlayReport, error) {
+	appLink := AppLink(proc)
+	s := LinkSwitch{Link: appLink}
+
+	relPath, err := filepath.Rel(filepath.Dir(appLink), revDir)
+	if err != nil {
+		return s, OverlayReport{}, fmt.Errorf("fail to create relative link : %s", err.Error())
+	}
+
+	_, err = EnsureManifest(revDir)
+	if err != nil {
+		return s, OverlayReport{}, fmt.Errorf("fail to write manifest : %s", err.Error())
+	}
+
+	report, err := ApplyOverlay(proc, revDir)
+	if err != nil {
+		return s, report, fmt.Errorf("fail to apply overlay : %s", err.Error())
+	}
+
+	s, err = SwitchLink(appLink, relPath)
+	return s, report, err
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 5:30
+ */
+
+package revision
+
+import (
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSwitchLink(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(dir, "sample")
+
+	first, err := SwitchLink(link, "revision/sample/R001")
+	assert.Nil(t, err)
+	assert.Equal(t, "", first.Previous)
+
+	second, err := SwitchLink(link, "revision/sample/R002")
+	assert.Nil(t, err)
+	assert.Equal(t, "revision/sample/R001", second.Previous)
+	target, _ := os.Readlink(link)
+	assert.Equal(t, "revision/sample/R002", target)
+
+	assert.Nil(t, second.Revert())
+	target, _ = os.Readlink(link)
+	assert.Equal(t, "revision/sample/R001", target)
+
+	assert.Nil(t, first.Revert())
+	_, err = os.Lstat(link)
+	assert.True(t, os.IsNotExist(err))
+
+	// 임시 link 가 남아있지 않아야 한다
+	entries, _ := os.ReadDir(dir)
+	assert.Equal(t, 0, len(entries))
+
+	// 실제 디렉토리는 교체하지 않는다
+	assert.Nil(t, os.Mkdir(link, 0755))
+	_, err = SwitchLink(link, "revision/sample/R001")
+	assert.NotNil(t, err)
+}
+
+func TestSwitchApplyOverlay(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+	r1 := filepath.Join(Dir("sample"), "2023.01.01-10.00_R001")
+	assert.Nil(t, os.MkdirAll(r1, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(r1, "sample.yaml"), []byte("port: 9190\n"), 0644))
+	assert.Nil(t, os.MkdirAll(filepath.Join(OverlayDir("sample")), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(OverlayDir("sample"), "sample.yaml"), []byte("port: 9191\n"), 0644))
+	assert.Nil(t, saveOverlay("sample", Overlay{Files: map[string]OverlayEntry{"sample.yaml": {Revision: "R000"}}}))
+
+	s, report, err := Switch("sample", r1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sample.yaml"}, report.Applied)
+	assert.Equal(t, AppLink("sample"), s.Link)
+
+	data, _ := os.ReadFile(filepath.Join(AppLink("sample"), "sample.yaml"))
+	assert.Equal(t, "port: 9191\n", string(data))
+
+	// link 할 때 baseline manifest 를 생성하고 적용한 overlay 를 반영한다
+	m, ok, err := LoadManifest(r1)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, ManifestSourceLocal, m.Source)
+	d, err := Verify(r1)
+	assert.Nil(t, err)
+	assert.True(t, d.IsEmpty())
+}
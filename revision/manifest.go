@@ -0,0 +1,280 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 7:00
+ */
+
+package revision
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// ManifestFileName revision 디렉토리에 생성되는 sha256 manifest 파일
+	ManifestFileName   = ".manifest.json"
+	DeploymentJsonFile = "deployment.json"
+
+	ManifestSourceLocal      = "local"      // revision 을 처음 link 하거나 import, dup 할 때 생성
+	ManifestSourceDeployment = "deployment" // far 의 deployment.json 에 포함된 manifest
+)
+
+var ErrNoManifest = errors.New("no baseline manifest")
+
+type Manifest struct {
+	Source  string            `json:"source"`
+	Created string            `json:"created"`
+	Files   map[string]string `json:"files"` // revision 디렉토리 기준 상대경로 -> sha256
+}
+
+// Diff 기준(from)과 비교 대상(to) 사이에 추가, 삭제, 변경된 파일 목록
+type Diff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func (d Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Compare 두 HashTree 결과를 비교한다
+func Compare(from, to map[string]string) Diff {
+	d := Diff{Added: make([]string, 0), Removed: make([]string, 0), Changed: make([]string, 0)}
+	for _, name := range SortedKeys(to) {
+		fromSum, ok := from[name]
+		if !ok {
+			d.Added = append(d.Added, name)
+		} else if fromSum != to[name] {
+			d.Changed = append(d.Changed, name)
+		}
+	}
+	for _, name := range SortedKeys(from) {
+		if _, ok := to[name]; !ok {
+			d.Removed = append(d.Removed, name)
+		}
+	}
+	return d
+}
+
+// HashFile 파일의 sha256 값을 hex 문자열로 리턴한다
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashTree dir 하위 모든 파일의 (dir 기준 상대경로 -> sha256) 를 구한다.
+// symlink 는 따라가지 않고 "link:<target>" 을 값으로 사용하며 manifest 파일과 runtime 폴더는 제외한다
+func HashTree(dir string) (map[string]string, error) {
+	tree := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel != "." && IsRuntimePath(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == ManifestFileName || IsRuntimePath(rel) {
+			return nil
+		}
+
+		if d.Type()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			tree[rel] = "link:" + target
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		sum, err := HashFile(path)
+		if err != nil {
+			return err
+		}
+		tree[rel] = sum
+		return nil
+	})
+	return tree, err
+}
+
+// SortedKeys map 의 key 를 정렬해서 리턴한다
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LoadManifest revision 디렉토리의 manifest 를 읽는다. 없을 경우 deployment.json 의 manifest 를 사용한다
+func LoadManifest(revDir string) (Manifest, bool, error) {
+	m := Manifest{}
+	data, err := os.ReadFile(filepath.Join(revDir, ManifestFileName))
+	if err == nil {
+		err = json.Unmarshal(data, &m)
+		if err != nil {
+			return m, false, fmt.Errorf("invalid manifest %s : %s", ManifestFileName, err.Error())
+		}
+		// 이전 버전에서 생성한 manifest 에는 pid 파일, 로그가 포함되어 있을 수 있다
+		m.Files = withoutRuntimePaths(m.Files)
+		return m, true, nil
+	}
+	if !os.IsNotExist(err) {
+		return m, false, err
+	}
+
+	data, err = os.ReadFile(filepath.Join(revDir, DeploymentJsonFile))
+	if err != nil {
+		return m, false, nil
+	}
+
+	deployment := struct {
+		Manifest map[string]string `json:"manifest,omitempty"`
+	}{}
+	if json.Unmarshal(data, &deployment) != nil || len(deployment.Manifest) == 0 {
+		return m, false, nil
+	}
+
+	m.Source = ManifestSourceDeployment
+	m.Files = withoutRuntimePaths(deployment.Manifest)
+	return m, true, nil
+}
+
+func withoutRuntimePaths(files map[string]string) map[string]string {
+	filtered := make(map[string]string)
+	for name, sum := range files {
+		if !IsRuntimePath(name) {
+			filtered[name] = sum
+		}
+	}
+	return filtered
+}
+
+// WriteManifest 현재 revision 디렉토리의 파일로 manifest 를 생성한다
+func WriteManifest(revDir string) (Manifest, error) {
+	m := Manifest{Source: ManifestSourceLocal, Created: time.Now().Format("2006-01-02 15:04:05")}
+
+	tree, err := HashTree(revDir)
+	if err != nil {
+		return m, fmt.Errorf("fail to hash %s : %s", revDir, err.Error())
+	}
+	m.Files = tree
+
+	return m, saveManifest(revDir, m)
+}
+
+// UpdateManifest files 의 hash 만 manifest 에 반영한다. manifest 가 없으면 새로 생성한다
+func UpdateManifest(revDir string, files []string) error {
+	m, ok, err := LoadManifest(revDir)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		_, err = WriteManifest(revDir)
+		return err
+	}
+
+	for _, rel := range files {
+		sum, err := HashFile(filepath.Join(revDir, filepath.FromSlash(rel)))
+		if err != nil {
+			return fmt.Errorf("fail to hash %s : %s", rel, err.Error())
+		}
+		m.Files[rel] = sum
+	}
+	return saveManifest(revDir, m)
+}
+
+func saveManifest(revDir string, m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = WriteFile(filepath.Join(revDir, ManifestFileName), data, 0644)
+	if err != nil {
+		return fmt.Errorf("fail to write manifest : %s", err.Error())
+	}
+	return nil
+}
+
+// EnsureManifest manifest 가 없으면 현재 파일로 baseline manifest 를 생성한다. 생성한 경우 true 를 리턴한다
+func EnsureManifest(revDir string) (bool, error) {
+	_, ok, err := LoadManifest(revDir)
+	if err != nil || ok {
+		return false, err
+	}
+
+	_, err = WriteManifest(revDir)
+	return err == nil, err
+}
+
+// Verify manifest 와 revision 디렉토리의 현재 파일을 비교한다.
+// manifest 가 없으면 비교할 기준이 없으므로 ErrNoManifest 를 리턴한다
+func Verify(revDir string) (Diff, error) {
+	m, ok, err := LoadManifest(revDir)
+	if err != nil {
+		return Diff{}, err
+	}
+	if !ok {
+		return Diff{}, ErrNoManifest
+	}
+
+	tree, err := HashTree(revDir)
+	if err != nil {
+		return Diff{}, fmt.Errorf("fail to hash %s : %s", revDir, err.Error())
+	}
+
+	if m.Source == ManifestSourceDeployment {
+		// deployment.json 은 자신의 hash 를 포함할 수 없다
+		delete(tree, DeploymentJsonFile)
+	}
+
+	return Compare(m.Files, tree), nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 7:00
+ */
+
+package revision
+
+import (
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sample"), []byte("binary"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sample.yaml"), []byte("a: 1\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "old.properties"), []byte("a=1\n"), 0644))
+
+	// manifest 가 없으면 생성하지 않고 기준이 없음을 알려준다
+	_, err := Verify(dir)
+	assert.Equal(t, ErrNoManifest, err)
+	assert.False(t, share.IsFileExist(filepath.Join(dir, ManifestFileName)))
+
+	created, err := EnsureManifest(dir)
+	assert.Nil(t, err)
+	assert.True(t, created)
+	created, err = EnsureManifest(dir)
+	assert.Nil(t, err)
+	assert.False(t, created)
+
+	d, err := Verify(dir)
+	assert.Nil(t, err)
+	assert.True(t, d.IsEmpty())
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sample.yaml"), []byte("a: 2\n"), 0644))
+	assert.Nil(t, os.Remove(filepath.Join(dir, "old.properties")))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "patch.jar"), []byte("jar"), 0644))
+
+	d, err = Verify(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"patch.jar"}, d.Added)
+	assert.Equal(t, []string{"old.properties"}, d.Removed)
+	assert.Equal(t, []string{"sample.yaml"}, d.Changed)
+}
+
+func TestVerifyIgnoreRuntimePaths(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sample"), []byte("binary"), 0755))
+	_, err := WriteManifest(dir)
+	assert.Nil(t, err)
+
+	// 기동 후 생성되는 pid 파일, 로그는 drift 가 아니다
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "proc"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "log"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "proc", "sample.pid"), []byte("100"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "log", "sample.out"), []byte("started"), 0644))
+
+	d, err := Verify(dir)
+	assert.Nil(t, err)
+	assert.True(t, d.IsEmpty())
+	assert.True(t, IsRuntimePath("proc/sample.pid"))
+	assert.False(t, IsRuntimePath("process.yaml"))
+}
+
+func TestVerifyDeploymentManifest(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sample"), []byte("binary"), 0755))
+	sum, _ := HashFile(filepath.Join(dir, "sample"))
+	deployment := `{"process":"sample","manifest":{"sample":"` + sum + `"}}`
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, DeploymentJsonFile), []byte(deployment), 0644))
+
+	d, err := Verify(dir)
+	assert.Nil(t, err)
+	assert.True(t, d.IsEmpty())
+	assert.False(t, share.IsFileExist(filepath.Join(dir, ManifestFileName)))
+}
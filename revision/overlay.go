@@ -0,0 +1,192 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 10:30
+ */
+
+package revision
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FolderOverlay       = "overlay"
+	OverlayInfoFileName = ".overlay.json"
+)
+
+// OverlayEntry overlay 파일을 capture 할 당시의 upstream 파일 정보
+type OverlayEntry struct {
+	Revision string `json:"revision"`
+	Base     string `json:"base"` // capture 당시 upstream 파일의 sha256. upstream 에 없던 파일이면 빈 문자열
+}
+
+type Overlay struct {
+	Files map[string]OverlayEntry `json:"files"`
+}
+
+type OverlayReport struct {
+	Applied   []string
+	Conflicts []string // capture 이후 upstream 에서도 변경된 파일
+}
+
+// OverlayDir $FATIMA_HOME/app/overlay/<proc>
+func OverlayDir(proc string) string {
+	return filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderApp, FolderOverlay, proc)
+}
+
+func LoadOverlay(proc string) (Overlay, error) {
+	overlay := Overlay{Files: make(map[string]OverlayEntry)}
+	data, err := os.ReadFile(filepath.Join(OverlayDir(proc), OverlayInfoFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return overlay, nil
+		}
+		return overlay, err
+	}
+
+	err = json.Unmarshal(data, &overlay)
+	if err != nil {
+		return overlay, fmt.Errorf("invalid overlay file %s : %s", OverlayInfoFileName, err.Error())
+	}
+	if overlay.Files == nil {
+		overlay.Files = make(map[string]OverlayEntry)
+	}
+	return overlay, nil
+}
+
+func saveOverlay(proc string, overlay Overlay) error {
+	data, err := json.MarshalIndent(overlay, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(OverlayDir(proc), OverlayInfoFileName), data, 0644)
+}
+
+// CaptureOverlay revision 디렉토리의 파일을 overlay 디렉토리에 저장한다.
+// files 가 없으면 manifest 와 비교해서 변경되거나 추가된 설정 파일을 저장한다.
+// pid 파일, 로그 같은 runtime 파일이나 바이너리는 명시적으로 지정해야 한다
+func CaptureOverlay(proc string, revDir string, revName string, files []string) ([]string, error) {
+	m, ok, err := LoadManifest(revDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		if !ok {
+			return nil, fmt.Errorf("there is no manifest in %s. specify files to capture", revName)
+		}
+		tree, err := HashTree(revDir)
+		if err != nil {
+			return nil, err
+		}
+		if m.Source == ManifestSourceDeployment {
+			delete(tree, DeploymentJsonFile)
+		}
+		d := Compare(m.Files, tree)
+		for _, f := range append(d.Changed, d.Added...) {
+			if IsConfigFile(f) && !IsRuntimePath(f) {
+				files = append(files, f)
+			}
+		}
+	}
+
+	overlay, err := LoadOverlay(proc)
+	if err != nil {
+		return nil, err
+	}
+
+	captured := make([]string, 0)
+	for _, f := range files {
+		rel := filepath.ToSlash(filepath.Clean(f))
+		if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			return captured, fmt.Errorf("invalid file path : %s", f)
+		}
+
+		dest := filepath.Join(OverlayDir(proc), filepath.FromSlash(rel))
+		err = os.MkdirAll(filepath.Dir(dest), 0755)
+		if err != nil {
+			return captured, err
+		}
+		err = CopyFile(filepath.Join(revDir, filepath.FromSlash(rel)), dest)
+		if err != nil {
+			return captured, fmt.Errorf("fail to capture %s : %s", rel, err.Error())
+		}
+
+		overlay.Files[rel] = OverlayEntry{Revision: revName, Base: m.Files[rel]}
+		captured = append(captured, rel)
+	}
+
+	return captured, saveOverlay(proc, overlay)
+}
+
+// ApplyOverlay overlay 파일을 revision 디렉토리에 적용한다.
+// capture 이후 upstream 파일도 변경된 경우 overlay 를 적용하고 conflict 로 알려준다
+func ApplyOverlay(proc string, revDir string) (OverlayReport, error) {
+	report := OverlayReport{Applied: make([]string, 0), Conflicts: make([]string, 0)}
+	overlay, err := LoadOverlay(proc)
+	if err != nil {
+		return report, err
+	}
+
+	for _, rel := range sortedOverlayFiles(overlay) {
+		src := filepath.Join(OverlayDir(proc), filepath.FromSlash(rel))
+		target := filepath.Join(revDir, filepath.FromSlash(rel))
+
+		overlaySum, err := HashFile(src)
+		if err != nil {
+			return report, fmt.Errorf("fail to read overlay %s : %s", rel, err.Error())
+		}
+		upstreamSum, _ := HashFile(target)
+		if upstreamSum == overlaySum {
+			continue
+		}
+		if upstreamSum != overlay.Files[rel].Base {
+			report.Conflicts = append(report.Conflicts, rel)
+		}
+
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return report, err
+		}
+		err = CopyFile(src, target)
+		if err != nil {
+			return report, fmt.Errorf("fail to apply overlay %s : %s", rel, err.Error())
+		}
+		report.Applied = append(report.Applied, rel)
+	}
+
+	if len(report.Applied) > 0 {
+		// overlay 적용은 의도된 변경이므로 적용한 파일만 manifest 에 반영한다.
+		// 그 외의 변경(drift)은 그대로 verify 에서 확인할 수 있어야 한다
+		err = UpdateManifest(revDir, report.Applied)
+	}
+	return report, err
+}
+
+func sortedOverlayFiles(overlay Overlay) []string {
+	m := make(map[string]string)
+	for k := range overlay.Files {
+		m[k] = ""
+	}
+	return SortedKeys(m)
+}
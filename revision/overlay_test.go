@@ -0,0 +1,87 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 10:30
+ */
+
+package revision
+
+import (
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+	r1 := filepath.Join(Dir("sample"), "2023.01.01-10.00_R001")
+	r2 := filepath.Join(Dir("sample"), "2023.01.02-10.00_R002")
+	for _, dir := range []string{r1, r2} {
+		assert.Nil(t, os.MkdirAll(dir, 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "sample.yaml"), []byte("port: 9190\n"), 0644))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "log.xml"), []byte("<log/>\n"), 0644))
+	}
+	// R002 에서 upstream 이 log.xml 을 변경했다
+	assert.Nil(t, os.WriteFile(filepath.Join(r2, "log.xml"), []byte("<log level=\"info\"/>\n"), 0644))
+
+	_, err := WriteManifest(r1)
+	assert.Nil(t, err)
+
+	// 운영중에 R001 의 설정을 직접 수정
+	assert.Nil(t, os.WriteFile(filepath.Join(r1, "sample.yaml"), []byte("port: 9191\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(r1, "log.xml"), []byte("<log level=\"debug\"/>\n"), 0644))
+
+	// runtime 파일과 바이너리는 기본 capture 대상이 아니다
+	assert.Nil(t, os.MkdirAll(filepath.Join(r1, share.FatimaFolderAppProc), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(r1, share.FatimaFolderAppProc, "sample.pid"), []byte("1234"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(r1, "sample"), []byte("binary"), 0755))
+
+	captured, err := CaptureOverlay("sample", r1, "R001", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"log.xml", "sample.yaml"}, captured)
+
+	// R002 의 sample.yaml 이 다른 revision 과 hardlink 되어 있어도 함께 바뀌지 않아야 한다
+	linked := filepath.Join(t.TempDir(), "sample.yaml")
+	assert.Nil(t, os.Link(filepath.Join(r2, "sample.yaml"), linked))
+
+	// R002 의 manifest 이후 발생한 drift 는 overlay 적용 후에도 남아 있어야 한다
+	_, err = WriteManifest(r2)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(r2, "drift.txt"), []byte("drift"), 0644))
+
+	report, err := ApplyOverlay("sample", r2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"log.xml", "sample.yaml"}, report.Applied)
+	assert.Equal(t, []string{"log.xml"}, report.Conflicts)
+
+	data, _ := os.ReadFile(filepath.Join(r2, "sample.yaml"))
+	assert.Equal(t, "port: 9191\n", string(data))
+	data, _ = os.ReadFile(linked)
+	assert.Equal(t, "port: 9190\n", string(data))
+
+	diff, err := Verify(r2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"drift.txt"}, diff.Added)
+	assert.Equal(t, 0, len(diff.Changed))
+
+	// 이미 적용된 revision 에는 다시 적용하지 않는다
+	report, err = ApplyOverlay("sample", r2)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(report.Applied))
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 6:30
+ */
+
+// Package revision $FATIMA_HOME/app/revision/<proc> 하위의 revision 관리 정보를 다룬다
+package revision
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fatima-go/fatima-cmd/share"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// PinFileName revision 폴더에 생성되는 pin marker 파일
+const PinFileName = ".pin"
+
+type Pin struct {
+	Revision string `json:"revision"`
+	User     string `json:"user"`
+	Time     string `json:"time"`
+}
+
+func (p Pin) String() string {
+	return fmt.Sprintf("pinned %s by %s at %s", p.Revision, p.User, p.Time)
+}
+
+// Dir $FATIMA_HOME/app/revision/<proc>
+func Dir(proc string) string {
+	return filepath.Join(os.Getenv(share.EnvFatimaHome), share.FatimaFolderApp, share.FatimaFolderRevision, proc)
+}
+
+func pinFile(proc string) string {
+	return filepath.Join(Dir(proc), PinFileName)
+}
+
+// ReadPin pin 이 설정되어 있는지 확인한다.
+// pin 파일이 없는 경우 이외의 에러는 pin 이 설정된 것으로 리턴한다(fail closed)
+func ReadPin(proc string) (Pin, bool, error) {
+	pin := Pin{}
+	data, err := os.ReadFile(pinFile(proc))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pin, false, nil
+		}
+		return pin, true, fmt.Errorf("fail to read pin file %s : %s", pinFile(proc), err.Error())
+	}
+
+	err = json.Unmarshal(data, &pin)
+	if err != nil {
+		return pin, true, fmt.Errorf("invalid pin file %s : %s", pinFile(proc), err.Error())
+	}
+	return pin, true, nil
+}
+
+// IsPinned pin 파일을 읽을 수 없는 경우도 pin 된 것으로 취급한다
+func IsPinned(proc string) bool {
+	_, pinned, _ := ReadPin(proc)
+	return pinned
+}
+
+func WritePin(proc string, rev string) (Pin, error) {
+	pin := Pin{Revision: rev, Time: time.Now().Format("2006-01-02 15:04:05")}
+	if u, err := user.Current(); err == nil {
+		pin.User = u.Username
+	}
+
+	data, err := json.MarshalIndent(pin, "", "  ")
+	if err != nil {
+		return pin, err
+	}
+
+	err = os.WriteFile(pinFile(proc), data, 0644)
+	if err != nil {
+		return pin, fmt.Errorf("fail to write pin file : %s", err.Error())
+	}
+	return pin, nil
+}
+
+func RemovePin(proc string) error {
+	err := os.Remove(pinFile(proc))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("fail to remove pin file : %s", err.Error())
+	}
+	return nil
+}
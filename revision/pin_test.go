@@ -0,0 +1,57 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 19. 오후 6:30
+ */
+
+package revision
+
+import (
+	"github.com/fatima-go/fatima-cmd/share"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestPin(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+	assert.Nil(t, os.MkdirAll(Dir("sample"), 0755))
+
+	assert.False(t, IsPinned("sample"))
+
+	_, err := WritePin("sample", "R016")
+	assert.Nil(t, err)
+	pin, pinned, err := ReadPin("sample")
+	assert.Nil(t, err)
+	assert.True(t, pinned)
+	assert.Equal(t, "R016", pin.Revision)
+
+	assert.Nil(t, RemovePin("sample"))
+	assert.False(t, IsPinned("sample"))
+	assert.Nil(t, RemovePin("sample"))
+}
+
+func TestReadPinFailClosed(t *testing.T) {
+	t.Setenv(share.EnvFatimaHome, t.TempDir())
+	// pin 파일을 읽을 수 없으면 pin 된 것으로 취급한다
+	assert.Nil(t, os.MkdirAll(pinFile("sample"), 0755))
+
+	_, pinned, err := ReadPin("sample")
+	assert.NotNil(t, err)
+	assert.True(t, pinned)
+	assert.True(t, IsPinned("sample"))
+}
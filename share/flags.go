@@ -40,6 +40,7 @@ const (
 	FatimaFolderApp        = "app"
 	FatimaFolderAppProc    = "proc"
 	FatimaFolderRevision   = "revision"
+	FatimaFolderLog        = "log"
 	FatimaShellGoaway      = "goaway.sh"
 )
 
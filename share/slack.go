@@ -0,0 +1,171 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 1:05
+ */
+
+package share
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	saturnWebhookFile = "data/saturn/webhook.slack"
+	SlackPartAlarm    = "alarm"
+	SlackPartEvent    = "event"
+	slackPostTimeout  = 5 * time.Second
+)
+
+type SlackConfig struct {
+	Active  bool   `json:"active"`
+	Url     string `json:"url"`
+	Channel string `json:"channel,omitempty"`
+}
+
+func (sc SlackConfig) String() string {
+	if len(sc.Channel) > 0 {
+		return fmt.Sprintf("activate: [%t], hookUri: [%s], channel: [%s]", sc.Active, sc.Url, sc.Channel)
+	}
+
+	return fmt.Sprintf("activate: [%t], hookUri: [%s]", sc.Active, sc.Url)
+}
+
+// SlackWebhookConfig saturn 의 slack webhook 설정. key 는 alarm, event 등의 part 이름이다
+type SlackWebhookConfig map[string]*SlackConfig
+
+// ActiveStatus part 별 active 상태를 리턴한다
+func (c SlackWebhookConfig) ActiveStatus() map[string]bool {
+	status := make(map[string]bool)
+	for k, v := range c {
+		status[k] = v.Active
+	}
+	return status
+}
+
+func GetSlackWebhookConfigPath() string {
+	return filepath.Join(os.Getenv(EnvFatimaHome), saturnWebhookFile)
+}
+
+func LoadSlackWebhookConfig() (SlackWebhookConfig, error) {
+	var config SlackWebhookConfig
+
+	dataBytes, err := os.ReadFile(GetSlackWebhookConfigPath())
+	if err != nil {
+		return config, err
+	}
+
+	err = json.Unmarshal(dataBytes, &config)
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+func SaveSlackWebhookConfig(config SlackWebhookConfig) error {
+	bytes, err := json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(GetSlackWebhookConfigPath(), bytes, 0755)
+}
+
+// SetSlackActive 모든 part 의 active 를 변경하고 변경 전 상태를 리턴한다
+func SetSlackActive(turnOn bool) (map[string]bool, error) {
+	config, err := LoadSlackWebhookConfig()
+	if err != nil {
+		return nil, fmt.Errorf("fail to load slack webhook file : %s", err.Error())
+	}
+
+	previous := config.ActiveStatus()
+	for _, v := range config {
+		v.Active = turnOn
+	}
+
+	err = SaveSlackWebhookConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("fail to save slack webhook file : %s", err.Error())
+	}
+	return previous, nil
+}
+
+// RestoreSlackActive SetSlackActive 이전의 part 별 active 상태로 되돌린다
+func RestoreSlackActive(previous map[string]bool) error {
+	config, err := LoadSlackWebhookConfig()
+	if err != nil {
+		return fmt.Errorf("fail to load slack webhook file : %s", err.Error())
+	}
+
+	for k, v := range config {
+		if active, ok := previous[k]; ok {
+			v.Active = active
+		}
+	}
+
+	err = SaveSlackWebhookConfig(config)
+	if err != nil {
+		return fmt.Errorf("fail to save slack webhook file : %s", err.Error())
+	}
+	return nil
+}
+
+// PostSlack part(alarm, event) 에 설정된 webhook 으로 메시지를 보낸다. 비활성화된 경우 보내지 않는다
+func PostSlack(part string, text string) error {
+	config, err := LoadSlackWebhookConfig()
+	if err != nil {
+		return fmt.Errorf("fail to load slack webhook file : %s", err.Error())
+	}
+
+	sc, ok := config[part]
+	if !ok {
+		return fmt.Errorf("not found %s part in slack webhook file", part)
+	}
+	if !sc.Active || len(sc.Url) == 0 {
+		return nil
+	}
+
+	message := map[string]string{"text": text}
+	if len(sc.Channel) > 0 {
+		message["channel"] = sc.Channel
+	}
+	b, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("fail to marshal to json : %s", err.Error())
+	}
+
+	client := http.Client{Timeout: slackPostTimeout}
+	resp, err := client.Post(sc.Url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("fail to post slack : %s", err.Error())
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook response status %d", resp.StatusCode)
+	}
+	return nil
+}
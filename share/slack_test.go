@@ -0,0 +1,52 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오전 1:05
+ */
+
+package share
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetSlackActive(t *testing.T) {
+	t.Setenv(EnvFatimaHome, t.TempDir())
+	assert.Nil(t, os.MkdirAll(filepath.Dir(GetSlackWebhookConfigPath()), 0755))
+	assert.Nil(t, SaveSlackWebhookConfig(SlackWebhookConfig{
+		"alarm": {Active: true, Url: "http://alarm"},
+		"event": {Active: false, Url: "http://event"},
+	}))
+
+	previous, err := SetSlackActive(false)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{"alarm": true, "event": false}, previous)
+
+	config, err := LoadSlackWebhookConfig()
+	assert.Nil(t, err)
+	assert.False(t, config["alarm"].Active)
+	assert.False(t, config["event"].Active)
+
+	assert.Nil(t, RestoreSlackActive(previous))
+	config, err = LoadSlackWebhookConfig()
+	assert.Nil(t, err)
+	assert.True(t, config["alarm"].Active)
+	assert.False(t, config["event"].Active)
+}
@@ -21,8 +21,10 @@
 package share
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"regexp"
@@ -222,3 +224,29 @@ func ToBytes(s string) (uint64, error) {
 
 	return bytes, nil
 }
+
+// AskYes y/n 을 입력받는다. 입력이 끝나면(EOF) no 로 판단한다
+func AskYes(question string) bool {
+	return askYes(os.Stdin, question)
+}
+
+func askYes(r io.Reader, question string) bool {
+	reader := bufio.NewReader(r)
+	for true {
+		fmt.Printf("%s (y/n) ", question)
+		text, err := reader.ReadString('\n')
+		if len(text) == 0 {
+			if err != nil {
+				return false
+			}
+			continue
+		}
+		answer := strings.ToLower(strings.Trim(text, "\r\n\t "))
+		if answer == "n" {
+			return false
+		} else if answer == "y" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright 2023 github.com/fatima-go
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * @project fatima-go
+ * @author jin
+ * @date 26. 10. 20. 오후 5:20
+ */
+
+package share
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestAskYes(t *testing.T) {
+	assert.True(t, askYes(strings.NewReader("y\n"), "continue?"))
+	assert.True(t, askYes(strings.NewReader(" Y \r\n"), "continue?"))
+	assert.False(t, askYes(strings.NewReader("n\n"), "continue?"))
+	// y, n 이 아니면 다시 묻는다
+	assert.True(t, askYes(strings.NewReader("\nyes\ny\n"), "continue?"))
+	// 입력이 끝나면 no
+	assert.False(t, askYes(strings.NewReader(""), "continue?"))
+	assert.False(t, askYes(strings.NewReader("maybe"), "continue?"))
+}